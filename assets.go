@@ -0,0 +1,123 @@
+// assets.go
+package main
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/veandco/go-sdl2/img"
+	"github.com/veandco/go-sdl2/mix"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+/*
+
+The assets(textures and their sidecar files, sounds, the campaign and the levels) are looked up by their paths(like "resources/tiles.png") in:
+
+	1. the assets directory of the user(the setting "assets"), if it is set
+	2. the directory of the executable
+	3. the assets built into the executable
+
+So the executable runs from anywhere, even without the resources directory, and any asset can be replaced
+by putting a file with the same path into the assets directory(or next to the executable).
+
+Files given on the command line(--campaign, --map, and the maps of those campaigns) are looked up
+in the working directory first, as usual.
+
+*/
+
+//go:embed resources/*.png resources/*.ogg resources/*.json resources/levels
+var EMBEDDED_ASSETS embed.FS
+
+type Assets struct {
+	dirs []string // searched in order, before the embedded assets
+}
+
+// overrideDir may be empty
+func NewAssets(overrideDir string) *Assets {
+	assets := &Assets{}
+	if overrideDir != "" {
+		assets.dirs = append(assets.dirs, overrideDir)
+	}
+	if executable, err := os.Executable(); err == nil {
+		if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+			executable = resolved
+		}
+		assets.dirs = append(assets.dirs, filepath.Dir(executable))
+	}
+	return assets
+}
+
+// the whole asset, and where it was found(for the error messages)
+func (assets *Assets) ReadFile(name string) ([]byte, string, error) {
+	if filepath.IsAbs(name) {
+		data, err := ioutil.ReadFile(name)
+		return data, name, err
+	}
+	for _, dir := range assets.dirs {
+		location := filepath.Join(dir, filepath.FromSlash(name))
+		data, err := ioutil.ReadFile(location)
+		if err == nil {
+			return data, location, nil
+		} else if !os.IsNotExist(err) {
+			return nil, location, err
+		}
+	}
+	embedded := path.Clean(filepath.ToSlash(name))
+	data, err := EMBEDDED_ASSETS.ReadFile(embedded)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, name, fmt.Errorf("%s not found(in %s, or in the built in assets)", name, strings.Join(assets.dirs, ", "))
+	}
+	return data, "built in " + embedded, err
+}
+
+// a file given by the user, in the working directory, or else in the assets
+func (assets *Assets) ReadUserFile(name string) ([]byte, error) {
+	data, err := ioutil.ReadFile(name)
+	if (err == nil) || !os.IsNotExist(err) {
+		return data, err
+	}
+	data, _, err = assets.ReadFile(name)
+	return data, err
+}
+
+//==============LOADING==============
+
+func (assets *Assets) LoadImage(name string) (*sdl.Surface, error) {
+	data, location, err := assets.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	rw, err := sdl.RWFromMem(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", location, err)
+	}
+	image, err := img.LoadRW(rw, true) // the image is decoded here, data is not needed after this
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", location, err)
+	}
+	return image, nil
+}
+
+func (assets *Assets) LoadSound(name string) (*mix.Chunk, error) {
+	data, location, err := assets.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	rw, err := sdl.RWFromMem(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", location, err)
+	}
+	chunk, err := mix.LoadWAVRW(rw, true)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", location, err)
+	}
+	return chunk, nil
+}
@@ -0,0 +1,89 @@
+// atlas.go
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+/*
+
+Every texture of the game is packed into one big texture(the atlas), when the textures are loaded.
+So the whole world can be drawn from one texture, in one batch(see batch.go), without switching textures.
+
+The images are packed in shelves(rows): the tallest images first, from left to right,
+and a new shelf is started, when an image does not fit in the current one.
+
+*/
+
+const (
+	ATLAS_WIDTH   int32 = 2048 // pixels, or the width of the widest image, if it is wider
+	ATLAS_PADDING int32 = 2    // pixels between the images, so that the smooth scaling does not bleed the neighbours in
+)
+
+// the places of the images(by their sizes) in the atlas, and the height of the atlas
+func packAtlas(sizes []sdl.Rect, width int32) ([]sdl.Rect, int32) {
+	order := make([]int, len(sizes))
+	for index := range order {
+		order[index] = index
+	}
+	sort.SliceStable(order, func(i, j int) bool { return sizes[order[i]].H > sizes[order[j]].H })
+
+	places := make([]sdl.Rect, len(sizes))
+	var x, y, shelfHeight int32
+	for _, index := range order {
+		size := sizes[index]
+		if (x > 0) && (x+size.W > width) {
+			// the next shelf
+			x = 0
+			y += shelfHeight + ATLAS_PADDING
+			shelfHeight = 0
+		}
+		places[index] = sdl.Rect{X: x, Y: y, W: size.W, H: size.H}
+		x += size.W + ATLAS_PADDING
+		if size.H > shelfHeight {
+			shelfHeight = size.H
+		}
+	}
+	return places, y + shelfHeight
+}
+
+/*Packs the images into one texture.
+Returns the texture, and the region of every image in it.*/
+func BuildAtlas(renderer *sdl.Renderer, images []*sdl.Surface) (*sdl.Texture, []sdl.Rect, error) {
+	width := ATLAS_WIDTH
+	sizes := make([]sdl.Rect, len(images))
+	for index, image := range images {
+		sizes[index] = sdl.Rect{W: image.W, H: image.H}
+		if image.W > width {
+			width = image.W
+		}
+	}
+	regions, height := packAtlas(sizes, width)
+	if info, err := renderer.GetInfo(); err == nil {
+		// zero means, there is no limit
+		if ((info.MaxTextureWidth > 0) && (width > info.MaxTextureWidth)) || ((info.MaxTextureHeight > 0) && (height > info.MaxTextureHeight)) {
+			return nil, nil, fmt.Errorf("the textures need a %dx%d atlas, the renderer supports only %dx%d", width, height, info.MaxTextureWidth, info.MaxTextureHeight)
+		}
+	}
+
+	atlas, err := sdl.CreateRGBSurfaceWithFormat(0, width, height, 32, sdl.PIXELFORMAT_ARGB8888)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer atlas.Free()
+	for index, image := range images {
+		image.SetBlendMode(sdl.BLENDMODE_NONE) // copying the alpha too, not blending it
+		if err := image.Blit(nil, atlas, &regions[index]); err != nil {
+			return nil, nil, err
+		}
+	}
+	texture, err := renderer.CreateTextureFromSurface(atlas)
+	if err != nil {
+		return nil, nil, err
+	}
+	texture.SetBlendMode(sdl.BLENDMODE_BLEND)
+	return texture, regions, nil
+}
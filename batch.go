@@ -0,0 +1,143 @@
+// batch.go
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/dev-abir/tanks/game"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+/*
+
+Drawing one sprite at a time(renderer.CopyEx) costs a call into SDL for every bullet, tank and explosion,
+which is too slow with thousands of bullets. So the sprites are collected in a batch, and drawn
+together with one SDL_RenderGeometry call(two triangles for every sprite), when the batch is flushed.
+The sprites are drawn in the order they were added, all of them from the atlas(see atlas.go).
+
+SDL_RenderGeometry needs SDL 2.0.18, with older versions(and in the static builds, see batch_geometry.go)
+every sprite is drawn by itself, as before.
+
+*/
+
+var NO_TINT sdl.Color = sdl.Color{R: 255, G: 255, B: 255, A: 255}
+
+// same memory layout as SDL_Vertex
+type vertex struct {
+	X, Y       float32
+	R, G, B, A uint8
+	U, V       float32
+}
+
+type batchSprite struct {
+	source sdl.Rect   // in the atlas
+	dest   game.Rect  // on the screen
+	angle  float32    // degrees, clockwise
+	centre game.Point // of the rotation, from the top left corner of dest
+	colour sdl.Color
+}
+
+type Batch struct {
+	renderer *sdl.Renderer
+	texture  *sdl.Texture // the atlas
+	width    float32      // of the atlas
+	height   float32
+	sprites  []batchSprite
+	vertices []vertex
+	indices  []int32
+
+	Batched bool // false draws every sprite by itself(to compare them in the benchmark)
+	Calls   int  // draw calls of the last flush
+}
+
+func NewBatch(renderer *sdl.Renderer, atlas *sdl.Texture) *Batch {
+	batch := &Batch{renderer: renderer, texture: atlas, Batched: true}
+	_, _, width, height, err := atlas.Query()
+	if err != nil {
+		HandleError("Cannot query the atlas, the sprites are drawn one by one: ", err)
+		batch.Batched = false
+	}
+	batch.width, batch.height = float32(width), float32(height)
+	return batch
+}
+
+// the sprite(source, in the atlas) is drawn at dest, rotated by angle around it's centre
+func (batch *Batch) Add(source sdl.Rect, dest game.Rect, angle float32, colour sdl.Color) {
+	batch.AddRotated(source, dest, angle, game.Point{X: dest.W / 2.0, Y: dest.H / 2.0}, colour)
+}
+
+// like Add, but rotated around centre(from the top left corner of dest)
+func (batch *Batch) AddRotated(source sdl.Rect, dest game.Rect, angle float32, centre game.Point, colour sdl.Color) {
+	batch.sprites = append(batch.sprites, batchSprite{source, dest, angle, centre, colour})
+}
+
+// draws the sprites, and empties the batch
+func (batch *Batch) Flush() {
+	batch.Calls = 0
+	if batch.Batched && (len(batch.sprites) > 0) {
+		batch.buildGeometry()
+		if renderGeometry(batch.renderer, batch.texture, batch.vertices, batch.indices) {
+			batch.Calls = 1
+			batch.sprites = batch.sprites[:0]
+			return
+		}
+		fmt.Println("The sprites can not be drawn in a batch(SDL 2.0.18 or newer is needed), they are drawn one by one")
+		batch.Batched = false
+	}
+
+	//==============ONE BY ONE==============
+	tint := NO_TINT
+	for _, sprite := range batch.sprites {
+		if sprite.colour != tint {
+			tint = sprite.colour
+			batch.texture.SetColorMod(tint.R, tint.G, tint.B)
+			batch.texture.SetAlphaMod(tint.A)
+		}
+		// TODO : For some reason CopyExF is not working.......
+		batch.renderer.CopyEx(batch.texture, &sprite.source,
+			&sdl.Rect{
+				X: int32(sprite.dest.X),
+				Y: int32(sprite.dest.Y),
+				W: int32(sprite.dest.W),
+				H: int32(sprite.dest.H)},
+			float64(sprite.angle), &sdl.Point{X: int32(sprite.centre.X), Y: int32(sprite.centre.Y)}, sdl.FLIP_NONE)
+		batch.Calls += 1
+	}
+	if tint != NO_TINT {
+		batch.texture.SetColorMod(NO_TINT.R, NO_TINT.G, NO_TINT.B)
+		batch.texture.SetAlphaMod(NO_TINT.A)
+	}
+	batch.sprites = batch.sprites[:0]
+}
+
+// two triangles for every sprite
+func (batch *Batch) buildGeometry() {
+	batch.vertices = batch.vertices[:0]
+	batch.indices = batch.indices[:0]
+	for _, sprite := range batch.sprites {
+		sin, cos := math.Sincos(game.DegreeToRadian(float64(sprite.angle)))
+		origin := game.Point{X: sprite.dest.X + sprite.centre.X, Y: sprite.dest.Y + sprite.centre.Y}
+		u0, v0 := float32(sprite.source.X)/batch.width, float32(sprite.source.Y)/batch.height
+		u1, v1 := float32(sprite.source.X+sprite.source.W)/batch.width, float32(sprite.source.Y+sprite.source.H)/batch.height
+
+		first := int32(len(batch.vertices))
+		for _, corner := range [4]struct{ x, y, u, v float32 }{
+			{0.0, 0.0, u0, v0},
+			{sprite.dest.W, 0.0, u1, v0},
+			{sprite.dest.W, sprite.dest.H, u1, v1},
+			{0.0, sprite.dest.H, u0, v1},
+		} {
+			// rotating the corner around the centre
+			x := float64(corner.x - sprite.centre.X)
+			y := float64(corner.y - sprite.centre.Y)
+			batch.vertices = append(batch.vertices, vertex{
+				X: origin.X + float32((x*cos)-(y*sin)),
+				Y: origin.Y + float32((x*sin)+(y*cos)),
+				R: sprite.colour.R, G: sprite.colour.G, B: sprite.colour.B, A: sprite.colour.A,
+				U: corner.u, V: corner.v,
+			})
+		}
+		batch.indices = append(batch.indices, first, first+1, first+2, first, first+2, first+3)
+	}
+}
@@ -0,0 +1,44 @@
+// batch_geometry.go
+//go:build !static
+// +build !static
+
+package main
+
+/*
+#cgo windows LDFLAGS: -lSDL2
+#cgo linux freebsd darwin openbsd pkg-config: sdl2
+
+#if defined(_WIN32)
+	#include <SDL2/SDL.h>
+#else
+	#include <SDL.h>
+#endif
+
+// vertices have the layout of SDL_Vertex(see vertex in batch.go)
+static int renderGeometry(SDL_Renderer *renderer, SDL_Texture *texture, const void *vertices, int numVertices, const int *indices, int numIndices) {
+#if SDL_VERSION_ATLEAST(2, 0, 18)
+	return SDL_RenderGeometry(renderer, texture, (const SDL_Vertex *)vertices, numVertices, indices, numIndices);
+#else
+	return -1;
+#endif
+}
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// returns false, if the geometry could not be drawn(SDL is older than 2.0.18)
+func renderGeometry(renderer *sdl.Renderer, texture *sdl.Texture, vertices []vertex, indices []int32) bool {
+	if len(indices) == 0 {
+		return true
+	}
+	return C.renderGeometry(
+		(*C.SDL_Renderer)(unsafe.Pointer(renderer)),
+		(*C.SDL_Texture)(unsafe.Pointer(texture)),
+		unsafe.Pointer(&vertices[0]), C.int(len(vertices)),
+		(*C.int)(unsafe.Pointer(&indices[0])), C.int(len(indices))) == 0
+}
@@ -0,0 +1,14 @@
+// batch_geometry_static.go
+//go:build static
+// +build static
+
+package main
+
+import "github.com/veandco/go-sdl2/sdl"
+
+/*The static builds(see release_script.sh) link the SDL libraries of go-sdl2,
+without their headers, so SDL_RenderGeometry can not be called from here.
+The sprites are drawn one by one, see Batch.Flush.*/
+func renderGeometry(renderer *sdl.Renderer, texture *sdl.Texture, vertices []vertex, indices []int32) bool {
+	return len(indices) == 0
+}
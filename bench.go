@@ -0,0 +1,155 @@
+// bench.go
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"golang.org/x/image/colornames"
+
+	"github.com/dev-abir/tanks/game"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+/*
+
+The benchmark scenes(see --bench-bullets), for measuring the game with much more, than a level ever has.
+
+*/
+
+const (
+	BENCH_SECONDS               float32 = 5.0  // of every way of drawing
+	BENCH_BULLETS_PER_EXPLOSION int     = 20   // and one explosion for this many bullets
+	BENCH_SIMULATED_SECONDS     float32 = 10.0 // of the simulation benchmark
+	BENCH_DEFAULT_BULLETS       int     = 2000 // in the simulation benchmark, if --bench-bullets is not given
+)
+
+/*Steps a world with this many tanks and bullets(see game.Benchmark) without a display,
+and prints how long the steps took.*/
+func BenchmarkSimulation(tanks int, bullets int, timestep float32, seed int64) {
+	bench := game.NewBenchmark(tanks, bullets, seed)
+	steps := int(BENCH_SIMULATED_SECONDS / timestep)
+	var total, slowest time.Duration
+	for step := 0; step < steps; step++ {
+		start := time.Now()
+		bench.Step(timestep)
+		took := time.Since(start)
+		total += took
+		if took > slowest {
+			slowest = took
+		}
+	}
+	average := total / time.Duration(steps)
+	fmt.Printf("%d tanks, %d bullets, %d steps: %v per step on average, %v at most(%.0f steps per second)\n",
+		len(bench.World.EnemyTanks), bullets, steps, average, slowest, float64(steps)/total.Seconds())
+	frame := time.Second / 60
+	if average < frame {
+		fmt.Printf("the simulation fits in a frame at 60 FPS(%v), with %v left for drawing\n", frame, frame-average)
+	} else {
+		fmt.Printf("the simulation is too slow for 60 FPS(%v per frame)\n", frame)
+	}
+}
+
+/*Draws a level with this many bullets flying around(and some explosions) for a while,
+first batched, then one by one, and prints the FPS of both.
+Returns false, if the window was closed or 'escape' was pressed.*/
+func BenchmarkRendering(renderer *sdl.Renderer, textures Textures, level *game.Level, bullets int, seed int64) bool {
+	random := rand.New(rand.NewSource(seed))
+	arena := game.Rect{W: float32(SCREEN_WIDTH), H: float32(SCREEN_HEIGHT)}
+	randomRect := func(size game.Size) game.Rect {
+		return game.Rect{
+			X: game.GetRandomFloat32(0.0, arena.W-size.W, random),
+			Y: game.GetRandomFloat32(0.0, arena.H-size.H, random),
+			W: size.W,
+			H: size.H,
+		}
+	}
+	bulletSize := game.Size{W: game.DEFAULT_BULLET_WIDTH, H: game.DEFAULT_BULLET_HEIGHT}
+	tankSize := game.Size{W: game.DEFAULT_TANK_WIDTH, H: game.DEFAULT_TANK_HEIGHT}
+
+	flying := make([]game.Bullet, bullets)
+	for index := range flying {
+		flying[index] = game.Bullet{
+			Velocity:      game.BULLET_VELOCITY,
+			BoundingBox:   randomRect(bulletSize),
+			RotationAngle: game.GetRandomFloat32(0.0, 360.0, random),
+		}
+	}
+	explosions := make([]game.Explosion, bullets/BENCH_BULLETS_PER_EXPLOSION)
+	for index := range explosions {
+		explosions[index] = game.NewExplosion(randomRect(tankSize), game.DEFAULT_EXPLOSION_FRAMES, game.EXPLOSION_ANIMATION_LIFE_SPAN)
+	}
+
+	batch := NewBatch(renderer, textures.atlas)
+	for _, batched := range []bool{true, false} {
+		batch.Batched = batched
+		frames, calls := 0, 0
+		start := time.Now()
+		last := start
+		for float32(time.Since(start).Seconds()) < BENCH_SECONDS {
+			for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+				switch t := event.(type) {
+				case *sdl.QuitEvent:
+					return false
+				case *sdl.KeyboardEvent:
+					if t.Keysym.Scancode == sdl.SCANCODE_ESCAPE {
+						return false
+					}
+				}
+			}
+			now := time.Now()
+			dt := float32(now.Sub(last).Seconds())
+			last = now
+
+			//==============UPDATING==============
+			for index := range flying {
+				bullet := &flying[index]
+				bullet.Update(dt)
+				if !bullet.BoundingBox.Centre().InRect(arena) {
+					bullet.BoundingBox = randomRect(bulletSize) // flying again, from somewhere else
+				}
+			}
+			for index := range explosions {
+				explosions[index].Update(dt)
+				if explosions[index].Died {
+					explosions[index] = game.NewExplosion(randomRect(tankSize), game.DEFAULT_EXPLOSION_FRAMES, game.EXPLOSION_ANIMATION_LIFE_SPAN)
+				}
+			}
+
+			//==============DRAWING==============
+			renderer.SetDrawColor(colornames.Bisque.R, colornames.Bisque.G, colornames.Bisque.B, colornames.Bisque.A)
+			renderer.Clear()
+			DrawTiles(batch, textures, level, false)
+			for index := range explosions {
+				DrawExplosion(batch, textures, explosions[index])
+			}
+			for index := range flying {
+				DrawTexture(batch, textures.bullet, &flying[index].BoundingBox, flying[index].RotationAngle, NO_TINT)
+			}
+			DrawTiles(batch, textures, level, true)
+			batch.Flush()
+			calls += batch.Calls
+
+			renderer.SetDrawColor(colornames.Black.R, colornames.Black.G, colornames.Black.B, colornames.Black.A)
+			name := "ONE BY ONE"
+			if batch.Batched {
+				name = "BATCHED"
+			}
+			DrawText(renderer, fmt.Sprintf("%s %d BULLETS", name, bullets), HUD_MARGIN, HUD_MARGIN, HUD_TEXT_SCALE)
+			renderer.Present()
+			frames += 1
+		}
+
+		seconds := time.Since(start).Seconds()
+		name := "one by one"
+		if batch.Batched {
+			name = "batched"
+		} else if batched {
+			name = "batched(not supported, drawn one by one)"
+		}
+		fmt.Printf("%d bullets, %d explosions, %s: %.1f FPS, %d draw calls per frame\n",
+			bullets, len(explosions), name, float64(frames)/seconds, calls/frames)
+	}
+	return true
+}
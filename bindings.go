@@ -0,0 +1,292 @@
+// bindings.go
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/dev-abir/tanks/game"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+/*
+
+The keys are bound to actions, and the bindings are saved in a JSON file in the config directory
+of the user(see BindingsPath), for example:
+
+	{
+		"singlePlayer": {"moveUp": "W", "rotateAntiClockWise": "Left", "fire": "Space", ...},
+		"twoPlayers": [{"moveUp": "W", ...}, {"moveUp": "I", ...}],
+		"pause": "P",
+		"controls": "F1",
+		"quit": "Escape"
+	}
+
+Keys are written by their SDL names(see SDL_GetScancodeName), missing actions keep their default keys.
+One player and two players have different keys(two players share one keyboard),
+but pause, controls and quit are the same for both.
+
+*/
+
+const USER_DIRECTORY string = "tanks" // in the config directory of the user
+
+type Action int
+
+const (
+	// actions of one player
+	ACTION_MOVE_UP Action = iota
+	ACTION_MOVE_LEFT
+	ACTION_MOVE_DOWN
+	ACTION_MOVE_RIGHT
+	ACTION_ROTATE_ANTI_CLOCK_WISE
+	ACTION_ROTATE_CLOCK_WISE
+	ACTION_FIRE
+	PLAYER_ACTIONS int = iota // number of actions of one player
+)
+
+// names in the bindings file, and on the controls screen
+var ACTION_NAMES [PLAYER_ACTIONS]string = [PLAYER_ACTIONS]string{"moveUp", "moveLeft", "moveDown", "moveRight", "rotateAntiClockWise", "rotateClockWise", "fire"}
+var ACTION_LABELS [PLAYER_ACTIONS]string = [PLAYER_ACTIONS]string{"MOVE UP", "MOVE LEFT", "MOVE DOWN", "MOVE RIGHT", "ROTATE LEFT", "ROTATE RIGHT", "FIRE"}
+
+// the keys of one player, by action
+type KeySet [PLAYER_ACTIONS]sdl.Scancode
+
+type Bindings struct {
+	SinglePlayer KeySet
+	TwoPlayers   [2]KeySet // two players share one keyboard, the first player on the left, the second one on the right
+	Pause        sdl.Scancode
+	Controls     sdl.Scancode // shows the controls screen, for changing the keys
+	Quit         sdl.Scancode
+
+	path string // where the bindings are saved, empty if they are not saved
+}
+
+const MAX_PLAYERS int = len(Bindings{}.TwoPlayers)
+
+var DEFAULT_BINDINGS Bindings = Bindings{
+	SinglePlayer: KeySet{
+		ACTION_MOVE_UP:                sdl.SCANCODE_W,
+		ACTION_MOVE_LEFT:              sdl.SCANCODE_A,
+		ACTION_MOVE_DOWN:              sdl.SCANCODE_S,
+		ACTION_MOVE_RIGHT:             sdl.SCANCODE_D,
+		ACTION_ROTATE_ANTI_CLOCK_WISE: sdl.SCANCODE_LEFT,
+		ACTION_ROTATE_CLOCK_WISE:      sdl.SCANCODE_RIGHT,
+		ACTION_FIRE:                   sdl.SCANCODE_SPACE,
+	},
+	TwoPlayers: [2]KeySet{
+		{
+			ACTION_MOVE_UP:                sdl.SCANCODE_W,
+			ACTION_MOVE_LEFT:              sdl.SCANCODE_A,
+			ACTION_MOVE_DOWN:              sdl.SCANCODE_S,
+			ACTION_MOVE_RIGHT:             sdl.SCANCODE_D,
+			ACTION_ROTATE_ANTI_CLOCK_WISE: sdl.SCANCODE_Q,
+			ACTION_ROTATE_CLOCK_WISE:      sdl.SCANCODE_E,
+			ACTION_FIRE:                   sdl.SCANCODE_SPACE,
+		},
+		{
+			ACTION_MOVE_UP:                sdl.SCANCODE_I,
+			ACTION_MOVE_LEFT:              sdl.SCANCODE_J,
+			ACTION_MOVE_DOWN:              sdl.SCANCODE_K,
+			ACTION_MOVE_RIGHT:             sdl.SCANCODE_L,
+			ACTION_ROTATE_ANTI_CLOCK_WISE: sdl.SCANCODE_U,
+			ACTION_ROTATE_CLOCK_WISE:      sdl.SCANCODE_O,
+			ACTION_FIRE:                   sdl.SCANCODE_RETURN,
+		},
+	},
+	Pause:    sdl.SCANCODE_P,
+	Controls: sdl.SCANCODE_F1,
+	Quit:     sdl.SCANCODE_ESCAPE,
+}
+
+// the held keys(firing is handled by the keyboard events, see PlayLevel)
+func (keys KeySet) Input(keyboardState []uint8) game.Input {
+	return game.Input{
+		RotateAntiClockWise: keyboardState[keys[ACTION_ROTATE_ANTI_CLOCK_WISE]] == 1,
+		RotateClockWise:     keyboardState[keys[ACTION_ROTATE_CLOCK_WISE]] == 1,
+		MoveUp:              keyboardState[keys[ACTION_MOVE_UP]] == 1,
+		MoveLeft:            keyboardState[keys[ACTION_MOVE_LEFT]] == 1,
+		MoveDown:            keyboardState[keys[ACTION_MOVE_DOWN]] == 1,
+		MoveRight:           keyboardState[keys[ACTION_MOVE_RIGHT]] == 1,
+	}
+}
+
+func (bindings *Bindings) KeySets(players int) []KeySet {
+	if players == 1 {
+		return []KeySet{bindings.SinglePlayer}
+	}
+	return bindings.TwoPlayers[:players]
+}
+
+// one bound key, on the controls screen
+type Binding struct {
+	Label string
+	Key   *sdl.Scancode
+}
+
+// every key used while playing with this many players
+func (bindings *Bindings) List(players int) []Binding {
+	var list []Binding
+	for index := range bindings.KeySets(players) {
+		keys := &bindings.SinglePlayer
+		prefix := ""
+		if players > 1 {
+			keys = &bindings.TwoPlayers[index]
+			prefix = fmt.Sprintf("P%d ", index+1)
+		}
+		for action := range keys {
+			list = append(list, Binding{prefix + ACTION_LABELS[action], &keys[action]})
+		}
+	}
+	return append(list,
+		Binding{"PAUSE", &bindings.Pause},
+		Binding{"CONTROLS", &bindings.Controls},
+		Binding{"QUIT", &bindings.Quit},
+	)
+}
+
+// returns an error, if a key is unknown, or it is bound to two actions(with the same number of players)
+func (bindings *Bindings) Validate() error {
+	for players := 1; players <= MAX_PLAYERS; players++ {
+		used := map[sdl.Scancode]string{}
+		for _, binding := range bindings.List(players) {
+			if *binding.Key == sdl.SCANCODE_UNKNOWN {
+				return fmt.Errorf("%s has no key", binding.Label)
+			}
+			if other, ok := used[*binding.Key]; ok {
+				return fmt.Errorf("%s is bound to both %s and %s", sdl.GetScancodeName(*binding.Key), other, binding.Label)
+			}
+			used[*binding.Key] = binding.Label
+		}
+	}
+	return nil
+}
+
+// the bindings file, with the names of the keys
+type bindingsFile struct {
+	SinglePlayer map[string]string   `json:"singlePlayer,omitempty"`
+	TwoPlayers   []map[string]string `json:"twoPlayers,omitempty"`
+	Pause        string              `json:"pause,omitempty"`
+	Controls     string              `json:"controls,omitempty"`
+	Quit         string              `json:"quit,omitempty"`
+}
+
+func keyNames(keys KeySet) map[string]string {
+	names := map[string]string{}
+	for action, key := range keys {
+		names[ACTION_NAMES[action]] = sdl.GetScancodeName(key)
+	}
+	return names
+}
+
+func parseKey(name string, key *sdl.Scancode) error {
+	if name == "" {
+		return nil // the default key
+	}
+	scancode := sdl.GetScancodeFromName(name)
+	if scancode == sdl.SCANCODE_UNKNOWN {
+		return fmt.Errorf("unknown key %q", name)
+	}
+	*key = scancode
+	return nil
+}
+
+func parseKeySet(names map[string]string, keys *KeySet) error {
+	for name, key := range names {
+		action := -1
+		for index := range ACTION_NAMES {
+			if ACTION_NAMES[index] == name {
+				action = index
+			}
+		}
+		if action == -1 {
+			return fmt.Errorf("unknown action %q", name)
+		}
+		if err := parseKey(key, &keys[action]); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// the bindings file in the config directory of the user(for example ~/.config/tanks/bindings.json on linux)
+func BindingsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, USER_DIRECTORY, "bindings.json"), nil
+}
+
+// loads the bindings from the file, if there is no file, the default bindings are used(and saved there, when they are changed)
+func LoadBindings(path string) (*Bindings, error) {
+	bindings := DEFAULT_BINDINGS
+	bindings.path = path
+	if path == "" {
+		return &bindings, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &bindings, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var file bindingsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if err := parseKeySet(file.SinglePlayer, &bindings.SinglePlayer); err != nil {
+		return nil, fmt.Errorf("singlePlayer: %v", err)
+	}
+	if len(file.TwoPlayers) > len(bindings.TwoPlayers) {
+		return nil, fmt.Errorf("twoPlayers: keys of %d players, only %d can play", len(file.TwoPlayers), len(bindings.TwoPlayers))
+	}
+	for index := range file.TwoPlayers {
+		if err := parseKeySet(file.TwoPlayers[index], &bindings.TwoPlayers[index]); err != nil {
+			return nil, fmt.Errorf("twoPlayers, player %d: %v", index+1, err)
+		}
+	}
+	for _, key := range []struct {
+		name     string
+		value    string
+		scancode *sdl.Scancode
+	}{
+		{"pause", file.Pause, &bindings.Pause},
+		{"controls", file.Controls, &bindings.Controls},
+		{"quit", file.Quit, &bindings.Quit},
+	} {
+		if err := parseKey(key.value, key.scancode); err != nil {
+			return nil, fmt.Errorf("%s: %v", key.name, err)
+		}
+	}
+	if err := bindings.Validate(); err != nil {
+		return nil, err
+	}
+	return &bindings, nil
+}
+
+func (bindings *Bindings) Save() error {
+	if bindings.path == "" {
+		return nil
+	}
+	file := bindingsFile{
+		SinglePlayer: keyNames(bindings.SinglePlayer),
+		Pause:        sdl.GetScancodeName(bindings.Pause),
+		Controls:     sdl.GetScancodeName(bindings.Controls),
+		Quit:         sdl.GetScancodeName(bindings.Quit),
+	}
+	for _, keys := range bindings.TwoPlayers {
+		file.TwoPlayers = append(file.TwoPlayers, keyNames(keys))
+	}
+	data, err := json.MarshalIndent(file, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(bindings.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(bindings.path, data, 0644)
+}
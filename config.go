@@ -0,0 +1,309 @@
+// config.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dev-abir/tanks/game"
+)
+
+/*
+
+The settings of the game come in layers, every layer overrides the one before:
+
+	1. the defaults(DefaultSettings)
+	2. the config file, tanks/config.json in the config directory of the user(or the file given by --config)
+	3. the environment variables, TANKS_<NAME>
+	4. the flags, --<name>
+
+Every setting has one name, for example "windowWidth" in the config file, TANKS_WINDOW_WIDTH in the environment
+and --window-width as a flag. The rules of the game(game.Rules) are in "rules" in the config file,
+and without a prefix elsewhere(TANKS_BULLET_VELOCITY, --bullet-velocity).
+The settings are validated after all the layers, --print-config prints them as a config file.
+
+*/
+
+type Settings struct {
+	//==============WINDOW==============
+	WindowWidth    int32 `json:"windowWidth" help:"width of the window(the arena is scaled to the window)"`
+	WindowHeight   int32 `json:"windowHeight" help:"height of the window"`
+	VSync          bool  `json:"vsync" help:"wait for the vertical sync of the display, before showing a frame"`
+	SmoothTextures bool  `json:"smoothTextures" help:"smooth(linear) scaling of the textures"`
+
+	//==============SIMULATION==============
+	Timestep         float32 `json:"timestep" help:"seconds, the world is always stepped with this dt"`
+	MaxStepsPerFrame int     `json:"maxStepsPerFrame" help:"if rendering is too slow, the game slows down, instead of freezing"`
+
+	//==============SCREENS==============
+	IntermissionTime   float32 `json:"intermissionTime" help:"seconds, the intermission screen is shown before every level(or until 'space' is pressed)"`
+	GameOverScreenTime float32 `json:"gameOverScreenTime" help:"seconds"`
+	ResultsScreenTime  float32 `json:"resultsScreenTime" help:"seconds, the results and the high scores are shown after the game(or until 'space' is pressed)"`
+
+	//==============GAME==============
+	Campaign         string  `json:"campaign" help:"campaign file to play(see resources/campaign.json)"`
+	Difficulty       string  `json:"difficulty" help:"how good the enemy tanks are at aiming(easy, normal or hard)"`
+	Players          int     `json:"players" help:"number of players on one keyboard"`
+	Mode             string  `json:"mode" help:"with more than one player: coop(fighting the enemy tanks together) or versus(fighting each other)"`
+	FriendlyFire     bool    `json:"friendlyFire" help:"in coop, the bullets of the players hurt each other too"`
+	Gamepads         string  `json:"gamepads" help:"players(1, 2...), who get the gamepads, in the order the gamepads are connected, for example \"2,1\"(every player in order, if empty)"`
+	Mouse            bool    `json:"mouse" help:"the first player aims with the mouse, and shoots with the left button"`
+	AutofireInterval float32 `json:"autofireInterval" help:"seconds between the shots, while the left mouse button is held"`
+	Pack             string  `json:"pack" help:"asset pack(textures and sounds), the name of a pack in the mods directory, \"menu\" chooses it on a screen"`
+	Mods             string  `json:"mods" help:"directory of the asset packs, tanks/mods in the config directory of the user, if empty"`
+	Assets           string  `json:"assets" help:"directory with your own assets(resources/...), they are used instead of the assets next to the executable, or built into it"`
+	Bindings         string  `json:"bindings" help:"key bindings file(the keys can be changed in the game, by pressing F1), bindings.json in the config directory of the user, if empty"`
+	HighScores       string  `json:"highScores" help:"high scores file, tanks/highscores.json in the data directory of the user, if empty"`
+
+	Rules game.Rules `json:"rules"`
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		WindowWidth:        SCREEN_WIDTH,
+		WindowHeight:       SCREEN_HEIGHT,
+		VSync:              VSYNC,
+		SmoothTextures:     SMOOTH_TEXTURES,
+		Timestep:           FIXED_TIMESTEP,
+		MaxStepsPerFrame:   MAX_STEPS_PER_FRAME,
+		IntermissionTime:   INTERMISSION_TIME,
+		GameOverScreenTime: GAME_OVER_SCREEN_TIME,
+		ResultsScreenTime:  RESULTS_SCREEN_TIME,
+		Campaign:           CAMPAIGN_PATH,
+		Difficulty:         game.DIFFICULTIES[game.DEFAULT_DIFFICULTY].Name,
+		Players:            1,
+		Mode:               game.MODE_NAMES[game.MODE_COOP],
+		Mouse:              true,
+		AutofireInterval:   AUTOFIRE_INTERVAL,
+		Pack:               DEFAULT_PACK_NAME,
+		Rules:              game.DefaultRules(),
+	}
+}
+
+func (settings Settings) Validate() error {
+	if err := game.CheckFinite(settings); err != nil {
+		return err
+	}
+	switch {
+	case (settings.WindowWidth <= 0) || (settings.WindowHeight <= 0):
+		return fmt.Errorf("invalid window size %dx%d", settings.WindowWidth, settings.WindowHeight)
+	case (settings.Timestep < game.MIN_TIMESTEP) || (settings.Timestep > 0.1):
+		return fmt.Errorf("timestep must be from %v to 0.1 seconds, not %v", game.MIN_TIMESTEP, settings.Timestep)
+	case settings.MaxStepsPerFrame < 1:
+		return errors.New("maxStepsPerFrame must be at least 1")
+	case (settings.IntermissionTime < 0.0) || (settings.GameOverScreenTime < 0.0) || (settings.ResultsScreenTime < 0.0):
+		return errors.New("intermissionTime, gameOverScreenTime and resultsScreenTime can not be negative")
+	case (settings.Players < 1) || (settings.Players > MAX_PLAYERS):
+		return fmt.Errorf("players must be from 1 to %d, not %d", MAX_PLAYERS, settings.Players)
+	case settings.AutofireInterval <= 0.0:
+		return errors.New("autofireInterval must be more than 0")
+	}
+	if _, err := game.FindDifficulty(settings.Difficulty); err != nil {
+		return err
+	}
+	if _, err := game.FindMode(settings.Mode); err != nil {
+		return err
+	}
+	if _, err := ParseGamepadOrder(settings.Gamepads, settings.Players); err != nil {
+		return fmt.Errorf("gamepads: %v", err)
+	}
+	if err := settings.Rules.Validate(); err != nil {
+		return fmt.Errorf("rules: %v", err)
+	}
+	return nil
+}
+
+//==============NAMES==============
+
+// one setting, a field of Settings(or game.Rules)
+type setting struct {
+	name  string // in the config file
+	help  string
+	value reflect.Value
+}
+
+// every setting, the fields of the nested structs are settings too
+func settingsOf(value reflect.Value) []setting {
+	var list []setting
+	for index := 0; index < value.NumField(); index++ {
+		field := value.Type().Field(index)
+		if field.Type.Kind() == reflect.Struct {
+			list = append(list, settingsOf(value.Field(index))...)
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		list = append(list, setting{name, field.Tag.Get("help"), value.Field(index)})
+	}
+	return list
+}
+
+func (settings *Settings) list() []setting {
+	return settingsOf(reflect.ValueOf(settings).Elem())
+}
+
+// "windowWidth" -> ["window", "width"]
+func splitName(name string) []string {
+	var words []string
+	start := 0
+	for index, character := range name {
+		if (index > 0) && unicode.IsUpper(character) {
+			words = append(words, strings.ToLower(name[start:index]))
+			start = index
+		}
+	}
+	return append(words, strings.ToLower(name[start:]))
+}
+
+func flagName(name string) string {
+	return strings.Join(splitName(name), "-")
+}
+
+func environmentName(name string) string {
+	return "TANKS_" + strings.ToUpper(strings.Join(splitName(name), "_"))
+}
+
+// sets the setting from it's text(in the environment, or a flag)
+func setSetting(value reflect.Value, text string) error {
+	switch value.Kind() {
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(text)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", text)
+		}
+		value.SetBool(parsed)
+	case reflect.Int, reflect.Int32:
+		parsed, err := strconv.ParseInt(text, 10, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", text)
+		}
+		value.SetInt(parsed)
+	case reflect.Float32:
+		parsed, err := strconv.ParseFloat(text, 32)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", text)
+		}
+		value.SetFloat(parsed)
+	case reflect.String:
+		value.SetString(text)
+	default:
+		panic("unsupported setting type " + value.Type().String())
+	}
+	return nil
+}
+
+//==============FLAGS==============
+
+// a flag of a setting, the value is applied after the config file and the environment(see LoadSettings)
+type settingFlag struct {
+	name         string
+	kind         reflect.Type
+	defaultValue string
+	value        *string // nil, if the flag was not given
+}
+
+func (f *settingFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.defaultValue
+}
+
+func (f *settingFlag) Set(text string) error {
+	if err := setSetting(reflect.New(f.kind).Elem(), text); err != nil {
+		return err
+	}
+	f.value = &text
+	return nil
+}
+
+func (f *settingFlag) IsBoolFlag() bool {
+	return (f != nil) && (f.kind != nil) && (f.kind.Kind() == reflect.Bool)
+}
+
+// the flags of the settings, given on the command line
+type SettingFlags []*settingFlag
+
+// adds a flag for every setting
+func RegisterSettingFlags(flags *flag.FlagSet) SettingFlags {
+	var list SettingFlags
+	defaults := DefaultSettings()
+	for _, s := range defaults.list() {
+		f := &settingFlag{name: s.name, kind: s.value.Type(), defaultValue: fmt.Sprint(s.value.Interface())}
+		flags.Var(f, flagName(s.name), fmt.Sprintf("%s(also %s, or %q in the config file)", s.help, environmentName(s.name), s.name))
+		list = append(list, f)
+	}
+	return list
+}
+
+//==============LOADING==============
+
+// the config file in the config directory of the user(for example ~/.config/tanks/config.json on linux)
+func SettingsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, USER_DIRECTORY, "config.json"), nil
+}
+
+/*Loads the settings from the config file(if it exists, or required is true),
+the environment variables and the flags, and validates them.*/
+func LoadSettings(path string, required bool, flags SettingFlags) (Settings, error) {
+	settings := DefaultSettings()
+
+	//==============CONFIG FILE==============
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) && !required {
+			// no config file, the defaults are used
+		} else if err != nil {
+			return settings, err
+		} else {
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			decoder.DisallowUnknownFields() // a misspelled setting should not be ignored silently
+			if err := decoder.Decode(&settings); err != nil {
+				return settings, fmt.Errorf("%s: %v", path, err)
+			}
+		}
+	}
+
+	//==============ENVIRONMENT==============
+	for _, s := range settings.list() {
+		if text, ok := os.LookupEnv(environmentName(s.name)); ok {
+			if err := setSetting(s.value, text); err != nil {
+				return settings, fmt.Errorf("%s: %v", environmentName(s.name), err)
+			}
+		}
+	}
+
+	//==============FLAGS==============
+	list := settings.list()
+	for index, f := range flags {
+		if f.value != nil {
+			if err := setSetting(list[index].value, *f.value); err != nil {
+				return settings, fmt.Errorf("--%s: %v", flagName(f.name), err)
+			}
+		}
+	}
+
+	return settings, settings.Validate()
+}
+
+// the settings as a config file
+func (settings Settings) String() string {
+	data, err := json.MarshalIndent(settings, "", "\t")
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
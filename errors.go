@@ -11,6 +11,19 @@ const (
 	ERROR_FAILED_TO_CREATE_RENDERER           int = 6
 	ERROR_FAILED_TO_LOAD_IMAGE                int = 7
 	ERROR_FAILED_TO_CREATE_TEXTURE_FROM_IMAGE int = 8
+	ERROR_FAILED_TO_READ_REPLAY               int = 9
+	ERROR_FAILED_TO_WRITE_REPLAY              int = 10
+	ERROR_FAILED_TO_LOAD_LEVEL                int = 11
+	ERROR_FAILED_TO_LOAD_CAMPAIGN             int = 12
+	// 13 is not used, an invalid difficulty is an invalid config(ERROR_INVALID_CONFIG)
+	ERROR_INVALID_GAME_MODE                   int = 14
+	ERROR_FAILED_TO_LOAD_BINDINGS             int = 15
+	ERROR_INVALID_CONFIG                      int = 16
+)
+
+const (
+	// not an error, but the exit code when the player tank has lost all of it's lives
+	PLAYER_LOST int = 3
 )
 
 func HandleError(message string, err error) {
@@ -0,0 +1,123 @@
+// font.go
+package main
+
+import (
+	"strings"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+/*
+
+A tiny built in bitmap font, so that we don't need a font file(and SDL_ttf) just to write a few words on the screen.
+Every glyph is 5x7 pixels, '#' is a filled pixel. Lower case letters are drawn as upper case.
+
+*/
+
+const (
+	FONT_GLYPH_WIDTH  int = 5
+	FONT_GLYPH_HEIGHT int = 7
+	FONT_GLYPH_SPACE  int = 1 // empty pixels between two glyphs
+)
+
+var FONT_GLYPHS map[rune][FONT_GLYPH_HEIGHT]string = map[rune][FONT_GLYPH_HEIGHT]string{
+	'A':  {".###.", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'B':  {"####.", "#...#", "#...#", "####.", "#...#", "#...#", "####."},
+	'C':  {".###.", "#...#", "#....", "#....", "#....", "#...#", ".###."},
+	'D':  {"####.", "#...#", "#...#", "#...#", "#...#", "#...#", "####."},
+	'E':  {"#####", "#....", "#....", "####.", "#....", "#....", "#####"},
+	'F':  {"#####", "#....", "#....", "####.", "#....", "#....", "#...."},
+	'G':  {".###.", "#...#", "#....", "#.###", "#...#", "#...#", ".####"},
+	'H':  {"#...#", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'I':  {".###.", "..#..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'J':  {"..###", "...#.", "...#.", "...#.", "...#.", "#..#.", ".##.."},
+	'K':  {"#...#", "#..#.", "#.#..", "##...", "#.#..", "#..#.", "#...#"},
+	'L':  {"#....", "#....", "#....", "#....", "#....", "#....", "#####"},
+	'M':  {"#...#", "##.##", "#.#.#", "#.#.#", "#...#", "#...#", "#...#"},
+	'N':  {"#...#", "#...#", "##..#", "#.#.#", "#..##", "#...#", "#...#"},
+	'O':  {".###.", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'P':  {"####.", "#...#", "#...#", "####.", "#....", "#....", "#...."},
+	'Q':  {".###.", "#...#", "#...#", "#...#", "#.#.#", "#..#.", ".##.#"},
+	'R':  {"####.", "#...#", "#...#", "####.", "#.#..", "#..#.", "#...#"},
+	'S':  {".####", "#....", "#....", ".###.", "....#", "....#", "####."},
+	'T':  {"#####", "..#..", "..#..", "..#..", "..#..", "..#..", "..#.."},
+	'U':  {"#...#", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'V':  {"#...#", "#...#", "#...#", "#...#", "#...#", ".#.#.", "..#.."},
+	'W':  {"#...#", "#...#", "#...#", "#.#.#", "#.#.#", "#.#.#", ".#.#."},
+	'X':  {"#...#", "#...#", ".#.#.", "..#..", ".#.#.", "#...#", "#...#"},
+	'Y':  {"#...#", "#...#", ".#.#.", "..#..", "..#..", "..#..", "..#.."},
+	'Z':  {"#####", "....#", "...#.", "..#..", ".#...", "#....", "#####"},
+	'0':  {".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+	'1':  {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'2':  {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	'3':  {"####.", "....#", "....#", ".###.", "....#", "....#", "####."},
+	'4':  {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	'5':  {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	'6':  {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	'7':  {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	'8':  {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+	'9':  {".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+	' ':  {".....", ".....", ".....", ".....", ".....", ".....", "....."},
+	':':  {".....", "..#..", "..#..", ".....", "..#..", "..#..", "....."},
+	'.':  {".....", ".....", ".....", ".....", ".....", "..#..", "..#.."},
+	',':  {".....", ".....", ".....", ".....", "..#..", "..#..", ".#..."},
+	'-':  {".....", ".....", ".....", ".###.", ".....", ".....", "....."},
+	'+':  {".....", "..#..", "..#..", "#####", "..#..", "..#..", "....."},
+	'=':  {".....", ".....", "#####", ".....", "#####", ".....", "....."},
+	'!':  {"..#..", "..#..", "..#..", "..#..", "..#..", ".....", "..#.."},
+	'?':  {".###.", "#...#", "....#", "...#.", "..#..", ".....", "..#.."},
+	'/':  {"....#", "....#", "...#.", "..#..", ".#...", "#....", "#...."},
+	'\'': {"..#..", "..#..", ".#...", ".....", ".....", ".....", "....."},
+	'(':  {"...#.", "..#..", ".#...", ".#...", ".#...", "..#..", "...#."},
+	')':  {".#...", "..#..", "...#.", "...#.", "...#.", "..#..", ".#..."},
+	'<':  {"...#.", "..#..", ".#...", "#....", ".#...", "..#..", "...#."},
+	'>':  {".#...", "..#..", "...#.", "....#", "...#.", "..#..", ".#..."},
+	'_':  {".....", ".....", ".....", ".....", ".....", ".....", "#####"},
+	'*':  {".....", "#.#.#", ".###.", "#####", ".###.", "#.#.#", "....."},
+	'%':  {"##..#", "##..#", "...#.", "..#..", ".#...", "#..##", "#..##"},
+}
+
+// width of the text in screen pixels, when it is drawn with this scale(size of one font pixel)
+func TextWidth(text string, scale int32) int32 {
+	length := int32(len([]rune(text)))
+	if length == 0 {
+		return 0
+	}
+	return (length*int32(FONT_GLYPH_WIDTH+FONT_GLYPH_SPACE) - int32(FONT_GLYPH_SPACE)) * scale
+}
+
+func TextHeight(scale int32) int32 {
+	return int32(FONT_GLYPH_HEIGHT) * scale
+}
+
+// draws the text with the current draw color of the renderer, x and y are of the top left corner
+func DrawText(renderer *sdl.Renderer, text string, x int32, y int32, scale int32) {
+	var rects []sdl.Rect
+	for _, character := range strings.ToUpper(text) {
+		glyph, ok := FONT_GLYPHS[character]
+		if !ok {
+			glyph = FONT_GLYPHS['?']
+		}
+		for row := 0; row < FONT_GLYPH_HEIGHT; row++ {
+			for column := 0; column < FONT_GLYPH_WIDTH; column++ {
+				if glyph[row][column] == '#' {
+					rects = append(rects, sdl.Rect{
+						X: x + int32(column)*scale,
+						Y: y + int32(row)*scale,
+						W: scale,
+						H: scale,
+					})
+				}
+			}
+		}
+		x += int32(FONT_GLYPH_WIDTH+FONT_GLYPH_SPACE) * scale
+	}
+	if len(rects) > 0 {
+		renderer.FillRects(rects)
+	}
+}
+
+// draws the text, horizontally centred on the screen
+func DrawTextCentred(renderer *sdl.Renderer, text string, y int32, scale int32) {
+	DrawText(renderer, text, (SCREEN_WIDTH-TextWidth(text, scale))/2, y, scale)
+}
@@ -0,0 +1,315 @@
+// ai.go
+package game
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+
+Every enemy tank is controlled by an AIController(see controller.go), with a Behaviour, which decides what the tank does in every step.
+The controller calls Update on every step, think is true when the think timer of the controller fires
+(see AIController.WillUpdate), behaviours should make their expensive intents(pathfinding, shooting)
+only then, and just follow those intents in the other steps.
+
+A behaviour keeps it's own state(the path it follows, the cell it patrols to...), so every tank
+gets a new one, from BEHAVIOURS. The level settings pick, which behaviours the enemy tanks of
+that level get(see LevelSettings.EnemyBehaviours), the difficulty decides how good they are at aiming.
+
+All of the randomness comes from world.r, so the behaviours keep the world deterministic.
+
+*/
+
+type Behaviour interface {
+	Update(world *World, tank *Tank, think bool) Intent
+}
+
+const (
+	BEHAVIOUR_RANDOM    string = "random"    // the classic enemy tank, it moves, turns and shoots randomly
+	BEHAVIOUR_PATROL    string = "patrol"    // moves between random places, and shoots the player when it sees it
+	BEHAVIOUR_CHASE     string = "chase"     // finds a path to the player, and shoots it
+	BEHAVIOUR_COWARD    string = "coward"    // chases the player, but runs away after getting damaged
+	BEHAVIOUR_ARTILLERY string = "artillery" // patrols, and stops to shoot the player from any distance, when it sees it
+)
+
+var BEHAVIOURS map[string]func() Behaviour = map[string]func() Behaviour{
+	BEHAVIOUR_RANDOM:    func() Behaviour { return &RandomBehaviour{} },
+	BEHAVIOUR_PATROL:    func() Behaviour { return &PatrolBehaviour{} },
+	BEHAVIOUR_CHASE:     func() Behaviour { return &ChaseBehaviour{} },
+	BEHAVIOUR_COWARD:    func() Behaviour { return &CowardBehaviour{} },
+	BEHAVIOUR_ARTILLERY: func() Behaviour { return &ArtilleryBehaviour{} },
+}
+
+//==============AI SETTINGS==============
+const (
+	AI_VELOCITY_SCALE    float32 = 0.25  // the enemy tanks move continuously, so they are slower than the random jumps of the classic tanks
+	AI_AIM_TOLERANCE     float32 = 10.0  // degrees, the enemy tank shoots only when it is aiming this close to the target
+	AI_WAYPOINT_DISTANCE float32 = 4.0   // pixels, a waypoint of the path has been reached
+	AI_CHASE_DISTANCE    float32 = 150.0 // pixels, chasing enemy tanks stop this close to the player, if they can see it
+	AI_PATROL_SIGHT      float32 = 250.0 // pixels, patrolling enemy tanks see the player this far
+	AI_RETREAT_SAMPLES   int     = 8     // number of random cells tried, to find a hiding place
+)
+
+//==============DIFFICULTY==============
+
+type Difficulty struct {
+	Name           string
+	AimError       float32 // degrees, the aim of the enemy tanks is off by a random angle up to this
+	LeadPrediction float32 // 0 to 1, how much the enemy tanks aim ahead of the moving player tank
+	ReactionTime   float32 // the think time of the enemy tanks is multiplied by this
+	ShootChance    float32 // 0 to 1, the chance of shooting, when an enemy tank can hit the player
+}
+
+var DIFFICULTIES []Difficulty = []Difficulty{
+	{Name: "easy", AimError: 20.0, LeadPrediction: 0.0, ReactionTime: 1.5, ShootChance: 0.4},
+	{Name: "normal", AimError: 8.0, LeadPrediction: 0.5, ReactionTime: 1.0, ShootChance: 0.7},
+	{Name: "hard", AimError: 2.0, LeadPrediction: 1.0, ReactionTime: 0.6, ShootChance: 1.0},
+}
+
+const DEFAULT_DIFFICULTY int = 1 // normal
+
+func FindDifficulty(name string) (int, error) {
+	for index, difficulty := range DIFFICULTIES {
+		if difficulty.Name == name {
+			return index, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown difficulty %q", name)
+}
+
+//==============HELPERS==============
+
+func angleTo(from Point, to Point) float32 {
+	return float32(math.Atan2(float64(to.Y-from.Y), float64(to.X-from.X)) * (180.0 / math.Pi))
+}
+
+func distance(from Point, to Point) float32 {
+	return float32(math.Hypot(float64(to.X-from.X), float64(to.Y-from.Y)))
+}
+
+func direction(from Point, to Point) Point {
+	length := distance(from, to)
+	if length == 0.0 {
+		return Point{}
+	}
+	return Point{(to.X - from.X) / length, (to.Y - from.Y) / length}
+}
+
+// the difference between two angles, from -180 to 180 degrees
+func AngleDifference(from float32, to float32) float32 {
+	difference := float32(math.Mod(float64(to-from), 360.0))
+	if difference > 180.0 {
+		difference -= 360.0
+	} else if difference <= -180.0 {
+		difference += 360.0
+	}
+	return difference
+}
+
+/*The angle to shoot at, to hit a target moving with this velocity(lead prediction).
+It solves |target + velocity*t - shooter| = bulletVelocity*t for the time t, when the bullet meets the target.
+If the bullet can never meet the target, it aims at the target directly.*/
+func interceptAngle(shooter Point, target Point, velocity Point, bulletVelocity float32, lead float32) float32 {
+	d := Point{target.X - shooter.X, target.Y - shooter.Y}
+	a := float64((velocity.X * velocity.X) + (velocity.Y * velocity.Y) - (bulletVelocity * bulletVelocity))
+	b := float64(2.0 * ((d.X * velocity.X) + (d.Y * velocity.Y)))
+	c := float64((d.X * d.X) + (d.Y * d.Y))
+	var t float64
+	if math.Abs(a) < 1e-6 { // the target is as fast as the bullet
+		if b >= 0.0 {
+			return angleTo(shooter, target)
+		}
+		t = -c / b
+	} else {
+		discriminant := (b * b) - (4.0 * a * c)
+		if discriminant < 0.0 {
+			return angleTo(shooter, target)
+		}
+		t1 := (-b - math.Sqrt(discriminant)) / (2.0 * a)
+		t2 := (-b + math.Sqrt(discriminant)) / (2.0 * a)
+		t = math.Min(t1, t2)
+		if t < 0.0 {
+			t = math.Max(t1, t2)
+		}
+		if t < 0.0 {
+			return angleTo(shooter, target)
+		}
+	}
+	t *= float64(lead)
+	return angleTo(shooter, Point{target.X + velocity.X*float32(t), target.Y + velocity.Y*float32(t)})
+}
+
+/*Aims at the player, when the tank can see it, and shoots on think, if the aim is good enough.
+Returns false, if the player can not be seen.*/
+func (world *World) aimAtPlayer(tank *Tank, think bool, intent *Intent) bool {
+	centre := tank.BoundingBox.Centre()
+	player := world.nearestPlayer(centre)
+	if player == nil {
+		return false
+	}
+	target := player.Tank.BoundingBox.Centre()
+	if !world.LineOfSight(centre, target) {
+		return false
+	}
+	difficulty := world.config.Difficulty
+	if think { // a new aim error, on every think
+		tank.aimError = GetRandomFloat32(-difficulty.AimError, difficulty.AimError, world.r)
+	}
+	intent.Aim = true
+	intent.AimAngle = interceptAngle(centre, target, player.velocity, tank.Weapon.velocityOf(world.config.Rules.BulletVelocity), difficulty.LeadPrediction) + tank.aimError
+	if think && (float32(math.Abs(float64(AngleDifference(tank.RotationAngle, intent.AimAngle)))) <= AI_AIM_TOLERANCE) {
+		intent.Fire = world.r.Float32() < difficulty.ShootChance
+	}
+	return true
+}
+
+/*Follows the path, turning towards where it goes.
+Returns false, when the end of the path has been reached.*/
+func (world *World) followPath(tank *Tank, path *[]Cell, intent *Intent) bool {
+	centre := tank.BoundingBox.Centre()
+	for len(*path) > 0 {
+		waypoint := world.CellCentre((*path)[0])
+		if distance(centre, waypoint) > AI_WAYPOINT_DISTANCE {
+			heading := direction(centre, waypoint)
+			intent.MoveX = heading.X * AI_VELOCITY_SCALE
+			intent.MoveY = heading.Y * AI_VELOCITY_SCALE
+			intent.Aim = true
+			intent.AimAngle = angleTo(centre, waypoint)
+			return true
+		}
+		*path = (*path)[1:]
+	}
+	return false
+}
+
+// a random cell, where the tank fits
+func (world *World) randomWalkableCell(tankSize Size) (Cell, bool) {
+	for i := 0; i < world.Level.Columns*world.Level.Rows; i++ {
+		cell := Cell{world.r.Intn(world.Level.Columns), world.r.Intn(world.Level.Rows)}
+		if world.walkable(cell, tankSize) {
+			return cell, true
+		}
+	}
+	return Cell{}, false
+}
+
+//==============BEHAVIOURS==============
+
+type RandomBehaviour struct{}
+
+func (behaviour *RandomBehaviour) Update(world *World, tank *Tank, think bool) Intent {
+	var intent Intent
+	if !think {
+		return intent
+	}
+	switch world.r.Intn(3) {
+	case 0: // a jump, for one step
+		direction := [4]Point{{0, 1}, {0, -1}, {1, 0}, {-1, 0}}[world.r.Intn(4)] // DOWN, UP, RIGHT, LEFT
+		intent.MoveX = direction.X
+		intent.MoveY = direction.Y
+	case 1:
+		intent.Aim = true
+		intent.AimAngle = world.r.Float32() * 360.0 // SHOOT ANYWHERE RANDOMLY
+	case 2:
+		intent.Fire = true
+	}
+	return intent
+}
+
+type PatrolBehaviour struct {
+	path []Cell
+}
+
+func (behaviour *PatrolBehaviour) Update(world *World, tank *Tank, think bool) Intent {
+	var intent Intent
+	if think && (len(behaviour.path) == 0) {
+		if goal, ok := world.randomWalkableCell(tank.BoundingBox.Size()); ok {
+			behaviour.path = world.FindPath(world.CellOf(tank.BoundingBox.Centre()), goal, tank.BoundingBox.Size())
+		}
+	}
+	world.followPath(tank, &behaviour.path, &intent)
+	if player := world.nearestPlayer(tank.BoundingBox.Centre()); (player != nil) && (distance(tank.BoundingBox.Centre(), player.Tank.BoundingBox.Centre()) <= AI_PATROL_SIGHT) {
+		world.aimAtPlayer(tank, think, &intent) // keeps patrolling, while shooting
+	}
+	return intent
+}
+
+type ChaseBehaviour struct {
+	path []Cell
+}
+
+func (behaviour *ChaseBehaviour) Update(world *World, tank *Tank, think bool) Intent {
+	var intent Intent
+	centre := tank.BoundingBox.Centre()
+	player := world.nearestPlayer(centre)
+	if player == nil {
+		return intent
+	}
+	if think { // the player moves, so the path is found again on every think
+		behaviour.path = world.FindPath(world.CellOf(centre), world.CellOf(player.Tank.BoundingBox.Centre()), tank.BoundingBox.Size())
+	}
+	if world.aimAtPlayer(tank, think, &intent) && (distance(centre, player.Tank.BoundingBox.Centre()) <= AI_CHASE_DISTANCE) {
+		return intent // close enough, just shooting
+	}
+	aim := intent
+	if world.followPath(tank, &behaviour.path, &intent) && aim.Aim {
+		intent.AimAngle = aim.AimAngle // looking at the player, while driving towards it
+	}
+	return intent
+}
+
+type CowardBehaviour struct {
+	ChaseBehaviour
+	retreating bool
+}
+
+func (behaviour *CowardBehaviour) Update(world *World, tank *Tank, think bool) Intent {
+	if tank.Health == tank.MaxHealth {
+		return behaviour.ChaseBehaviour.Update(world, tank, think)
+	}
+
+	var intent Intent
+	player := world.nearestPlayer(tank.BoundingBox.Centre())
+	if (player != nil) && !behaviour.retreating { // damaged, running away to the farthest of a few random cells
+		behaviour.retreating = true
+		var best Cell
+		bestDistance := float32(-1.0)
+		for i := 0; i < AI_RETREAT_SAMPLES; i++ {
+			cell, ok := world.randomWalkableCell(tank.BoundingBox.Size())
+			if d := distance(world.CellCentre(cell), player.Tank.BoundingBox.Centre()); ok && (d > bestDistance) {
+				best, bestDistance = cell, d
+			}
+		}
+		if bestDistance >= 0.0 {
+			behaviour.path = world.FindPath(world.CellOf(tank.BoundingBox.Centre()), best, tank.BoundingBox.Size())
+		}
+	}
+	if !world.followPath(tank, &behaviour.path, &intent) {
+		world.aimAtPlayer(tank, think, &intent) // cornered, fighting back
+	}
+	return intent
+}
+
+type ArtilleryBehaviour struct {
+	PatrolBehaviour
+}
+
+func (behaviour *ArtilleryBehaviour) Update(world *World, tank *Tank, think bool) Intent {
+	var intent Intent
+	if world.aimAtPlayer(tank, think, &intent) {
+		return intent // standing still, while shooting
+	}
+	return behaviour.PatrolBehaviour.Update(world, tank, think)
+}
+
+//==============CHOOSING BEHAVIOURS==============
+
+// picks a behaviour by the weights, "random" if there are no weights
+func (world *World) newBehaviour(weights map[string]float32) Behaviour {
+	name, weighted := world.pickByWeight(weights)
+	if !weighted {
+		return BEHAVIOURS[BEHAVIOUR_RANDOM]()
+	}
+	return BEHAVIOURS[name]()
+}
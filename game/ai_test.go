@@ -0,0 +1,236 @@
+package game
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// a small tank, which fits in one tile(the path can go through one tile wide gaps)
+var SMALL_TANK Size = Size{20, 20}
+
+// a wall of these tiles down the column, from the top row to the bottom one(included)
+func wallRows(column int, top int, bottom int, tile rune) []string {
+	rows := make([]string, LEVEL_ROWS)
+	for row := range rows {
+		line := []rune(strings.Repeat(".", LEVEL_COLUMNS))
+		if (row >= top) && (row <= bottom) {
+			line[column] = tile
+		}
+		rows[row] = string(line)
+	}
+	return rows
+}
+
+// a world with one player, no enemy tanks(see quietWorld) and this level
+func levelWorld(t *testing.T, rows ...string) *World {
+	world := quietWorld([]PlayerConfig{{}})
+	world.Level = mustParseLevel(t, levelText(rows...))
+	return world
+}
+
+// every cell of the path is walkable, and one step from the one before it
+func checkPath(t *testing.T, world *World, start Cell, path []Cell, size Size) {
+	t.Helper()
+	previous := start
+	for _, cell := range path {
+		if !world.walkable(cell, size) {
+			t.Errorf("the path goes through %v, where the tank does not fit", cell)
+		}
+		if (math.Abs(float64(cell.Column-previous.Column)) > 1) || (math.Abs(float64(cell.Row-previous.Row)) > 1) || (cell == previous) {
+			t.Errorf("the path jumps from %v to %v", previous, cell)
+		}
+		previous = cell
+	}
+}
+
+func TestFindPathAroundAWall(t *testing.T) {
+	tests := []struct {
+		name string
+		size Size
+	}{
+		{"small tank", SMALL_TANK},
+		{"tank", Size{DEFAULT_TANK_WIDTH, DEFAULT_TANK_HEIGHT}},
+	}
+	// steel down the middle, open at the bottom
+	world := levelWorld(t, wallRows(10, 0, 14, 'S')...)
+	start, goal := Cell{5, 5}, Cell{15, 5}
+	for _, test := range tests {
+		path := world.FindPath(start, goal, test.size)
+		if (len(path) == 0) || (path[len(path)-1] != goal) {
+			t.Fatalf("%s: no path from %v to %v, around the wall: %v", test.name, start, goal, path)
+		}
+		checkPath(t, world, start, path, test.size)
+		lowest := 0
+		for _, cell := range path {
+			if cell.Row > lowest {
+				lowest = cell.Row
+			}
+		}
+		if lowest < 15 {
+			t.Errorf("%s: the path does not go around the bottom of the wall, it's lowest row is %d", test.name, lowest)
+		}
+	}
+
+	// without the wall, the path is straight
+	open := levelWorld(t)
+	if path := open.FindPath(start, goal, SMALL_TANK); len(path) != goal.Column-start.Column {
+		t.Errorf("a straight path of %d cells, expected %d: %v", len(path), goal.Column-start.Column, path)
+	}
+}
+
+func TestFindPathUnreachable(t *testing.T) {
+	// water all the way down, the tank can not cross it
+	world := levelWorld(t, wallRows(10, 0, LEVEL_ROWS-1, 'W')...)
+	start, goal := Cell{5, 5}, Cell{15, 5}
+	path := world.FindPath(start, goal, SMALL_TANK)
+	for _, cell := range path {
+		if cell.Column >= 10 {
+			t.Fatalf("the path crosses the water at %v", cell)
+		}
+	}
+	if (len(path) > 0) && (path[len(path)-1] == goal) {
+		t.Error("found a path to a goal behind the water")
+	}
+	checkPath(t, world, start, path, SMALL_TANK)
+
+	// boxed in, there is nowhere to go
+	rows := levelText(
+		"....................",
+		"....................",
+		"....................",
+		"....................",
+		"....SSS.............",
+		"....S.S.............",
+		"....SSS.............",
+	)
+	boxed := levelWorld(t, strings.Split(rows, "\n")...)
+	if path := boxed.FindPath(start, goal, SMALL_TANK); path != nil {
+		t.Errorf("a path out of a closed box: %v", path)
+	}
+	// no cutting corners between two walls
+	corner := levelWorld(t, strings.Split(levelText(
+		"....................",
+		"....................",
+		"....................",
+		"....................",
+		"....................",
+		".....S..............",
+		"....S...............",
+	), "\n")...)
+	if path := corner.FindPath(Cell{4, 5}, Cell{5, 6}, SMALL_TANK); (len(path) == 1) && (path[0] == Cell{5, 6}) {
+		t.Error("the path went diagonally between two walls")
+	}
+}
+
+func TestLineOfSight(t *testing.T) {
+	from, to := Point{50, 130}, Point{450, 130}
+	tests := []struct {
+		tile  rune
+		sight bool
+	}{
+		{'.', true},
+		{'S', false},
+		{'B', false},
+		{'W', true}, // bullets fly over water
+		{'T', true},
+	}
+	for _, test := range tests {
+		world := levelWorld(t, wallRows(10, 0, LEVEL_ROWS-1, test.tile)...)
+		if sight := world.LineOfSight(from, to); sight != test.sight {
+			t.Errorf("across '%c': line of sight %v, expected %v", test.tile, sight, test.sight)
+		}
+		if sight := world.LineOfSight(to, from); sight != test.sight {
+			t.Errorf("across '%c', the other way: line of sight %v, expected %v", test.tile, sight, test.sight)
+		}
+	}
+	// the wall is not between the points
+	world := levelWorld(t, wallRows(10, 0, LEVEL_ROWS-1, 'S')...)
+	if !world.LineOfSight(Point{50, 130}, Point{200, 400}) {
+		t.Error("a wall to the right blocks the sight on the left")
+	}
+}
+
+func TestInterceptAngle(t *testing.T) {
+	shooter, target := Point{0, 0}, Point{100, 0}
+	down := Point{0, 100} // pixels per second
+	tests := []struct {
+		name     string
+		velocity Point
+		bullet   float32
+		lead     float32
+		angle    float32
+	}{
+		{"standing still", Point{}, 200, 1.0, 0.0},
+		// the bullet meets it after 1/sqrt(3) seconds, at (100, 57.7)
+		{"moving down", down, 200, 1.0, 30.0},
+		{"moving up", Point{0, -100}, 200, 1.0, -30.0},
+		{"no lead", down, 200, 0.0, 0.0},
+		{"half lead", down, 200, 0.5, float32(math.Atan(50/math.Sqrt(3)/100) * 180 / math.Pi)},
+		// a bullet as fast as the target, which comes closer, they meet at (50, 50)
+		{"coming closer", Point{-100, 100}, float32(100 * math.Sqrt2), 1.0, 45.0},
+		// a target running away faster than the bullet can never be hit, it is aimed at directly
+		{"running away", Point{500, 0}, 200, 1.0, 0.0},
+	}
+	for _, test := range tests {
+		angle := interceptAngle(shooter, target, test.velocity, test.bullet, test.lead)
+		if math.Abs(float64(angle-test.angle)) > 0.01 {
+			t.Errorf("%s: angle %f, expected %f", test.name, angle, test.angle)
+		}
+	}
+
+	// the bullet really meets the target
+	angle := float64(interceptAngle(shooter, target, down, 200, 1.0)) * (math.Pi / 180.0)
+	meet := 1.0 / math.Sqrt(3)
+	bullet := Point{float32(200 * math.Cos(angle) * meet), float32(200 * math.Sin(angle) * meet)}
+	if d := distance(bullet, Point{100, float32(100 * meet)}); d > 0.1 {
+		t.Errorf("the bullet misses the target by %f pixels", d)
+	}
+}
+
+func TestChaseBehaviour(t *testing.T) {
+	// the player is behind the wall, the enemy tank drives around it
+	world := levelWorld(t, wallRows(10, 0, 14, 'S')...)
+	player := &world.Players[0].Tank
+	player.BoundingBox.X, player.BoundingBox.Y = world.CellCentre(Cell{15, 5}).X-30, world.CellCentre(Cell{15, 5}).Y-30
+	placeEnemyTank(world, Point{world.CellCentre(Cell{5, 5}).X - 30, world.CellCentre(Cell{5, 5}).Y - 30}, 1)
+	tank := &world.EnemyTanks[0]
+	behaviour := &ChaseBehaviour{}
+	intent := behaviour.Update(world, tank, true)
+	if (len(behaviour.path) == 0) || (behaviour.path[len(behaviour.path)-1] != (Cell{15, 5})) {
+		t.Fatalf("the chasing tank did not find the path to the player: %v", behaviour.path)
+	}
+	if (intent.MoveX == 0.0) && (intent.MoveY == 0.0) {
+		t.Error("the chasing tank does not move")
+	}
+	if intent.Fire {
+		t.Error("the chasing tank shoots at the player through the wall")
+	}
+
+	// close and in sight, it stops and aims at the player
+	open := levelWorld(t)
+	player = &open.Players[0].Tank
+	placeEnemyTank(open, Point{player.BoundingBox.X - 100, player.BoundingBox.Y}, 1)
+	intent = (&ChaseBehaviour{}).Update(open, &open.EnemyTanks[0], true)
+	if (intent.MoveX != 0.0) || (intent.MoveY != 0.0) {
+		t.Errorf("the chasing tank moves(%f, %f), when it is close enough to shoot", intent.MoveX, intent.MoveY)
+	}
+	aimError := float64(open.config.Difficulty.AimError)
+	if !intent.Aim || (math.Abs(float64(AngleDifference(0.0, intent.AimAngle))) > aimError) {
+		t.Errorf("the chasing tank aims at %f degrees, expected the player to the right(0 degrees, give or take %f)", intent.AimAngle, aimError)
+	}
+}
+
+func TestArtilleryBehaviour(t *testing.T) {
+	// the player is far away in the open, artillery stands still and aims at it
+	world := levelWorld(t)
+	player := &world.Players[0].Tank
+	placeEnemyTank(world, Point{player.BoundingBox.X, player.BoundingBox.Y - 300}, 1)
+	intent := (&ArtilleryBehaviour{}).Update(world, &world.EnemyTanks[0], true)
+	if (intent.MoveX != 0.0) || (intent.MoveY != 0.0) || !intent.Aim {
+		t.Errorf("artillery in sight of the player: move(%f, %f), aim %v, expected it to stand still and aim", intent.MoveX, intent.MoveY, intent.Aim)
+	}
+	if difference := math.Abs(float64(AngleDifference(90.0, intent.AimAngle))); difference > float64(world.config.Difficulty.AimError) {
+		t.Errorf("artillery aims at %f degrees, expected the player below it(90 degrees)", intent.AimAngle)
+	}
+}
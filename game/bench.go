@@ -0,0 +1,93 @@
+// bench.go
+package game
+
+import (
+	"math"
+	"math/rand"
+)
+
+/*
+
+A world much bigger and busier than any level, for measuring the speed of the simulation
+without a display(see --bench-tanks of the main package).
+
+*/
+
+const (
+	BENCH_TANK_AREA       float32 = 6    // the arena has this many times the area of the tanks
+	BENCH_PLAYER_BULLETS  float32 = 0.5  // part of the bullets, shot by the player(the rest are shot by the enemy tanks)
+	BENCH_INDESTRUCTIBLE  int     = 1e9  // health of the tanks, so that they stay until the end of the benchmark
+	BENCH_BULLET_VELOCITY float32 = 0.25 // part of Rules.BulletVelocity, so that the bullets stay in the arena for a while
+)
+
+type Benchmark struct {
+	World   *World
+	bullets int // kept flying, new bullets are shot as the old ones hit or leave the arena
+	r       *rand.Rand
+}
+
+// an empty arena, with one(indestructible) player tank standing still, and exactly this many enemy tanks(if they fit) and bullets
+func NewBenchmark(tanks int, bullets int, seed int64) *Benchmark {
+	config := DefaultConfig()
+	side := float32(math.Sqrt(float64(float32(tanks) * BENCH_TANK_AREA * config.EnemyTankSize.W * config.EnemyTankSize.H)))
+	if side > config.ArenaWidth {
+		config.ArenaWidth, config.ArenaHeight = side, side
+	}
+	config.LevelSettings.MaxNumOfEnemyTanks = tanks + 2 // NewWorld starts with at least 2 tanks
+	config.LevelSettings.EnemyTankHealth = BENCH_INDESTRUCTIBLE
+	config.Rules.PlayerTankMaxHealth = BENCH_INDESTRUCTIBLE
+	world := NewWorld(config, seed)
+
+	// with only one tank, NewWorld has started with too many
+	for len(world.EnemyTanks) > tanks {
+		last := len(world.EnemyTanks) - 1
+		world.enemyTankGrid.Remove(last, world.EnemyTanks[last].Hull().Bounds())
+		world.EnemyTanks = world.EnemyTanks[:last]
+	}
+
+	// every tank at once
+	for len(world.EnemyTanks) < tanks {
+		tank := world.newEnemyTank()
+		boundingBox, ok := world.GetPositionOfOneEnemyTank(tank.BoundingBox, tank.RotationAngle, world.playerTankHulls(-1))
+		if !ok {
+			break // the arena is full, the benchmark runs with fewer tanks
+		}
+		tank.BoundingBox = boundingBox
+		world.EnemyTanks = append(world.EnemyTanks, tank)
+		world.enemyTankGrid.Insert(len(world.EnemyTanks)-1, tank.Hull().Bounds())
+	}
+	world.numOfEnemyTanksSpawned = world.config.LevelSettings.MaxNumOfEnemyTanks // no more spawning
+
+	bench := &Benchmark{World: world, bullets: bullets, r: rand.New(rand.NewSource(seed))}
+	bench.shoot()
+	return bench
+}
+
+// new bullets from random places, in random directions, until there are enough of them
+func (bench *Benchmark) shoot() {
+	world := bench.World
+	player := &world.Players[0]
+	for len(player.Bullets)+len(world.EnemyTankBullets) < bench.bullets {
+		bullet := Bullet{
+			Velocity: world.config.Rules.BulletVelocity * BENCH_BULLET_VELOCITY,
+			Power:    BULLET_POWER,
+			BoundingBox: Rect{
+				X: GetRandomFloat32(0.0, world.config.ArenaWidth-world.config.BulletSize.W, bench.r),
+				Y: GetRandomFloat32(0.0, world.config.ArenaHeight-world.config.BulletSize.H, bench.r),
+				W: world.config.BulletSize.W,
+				H: world.config.BulletSize.H,
+			},
+			RotationAngle: GetRandomFloat32(0.0, 360.0, bench.r),
+		}
+		if bench.r.Float32() < BENCH_PLAYER_BULLETS {
+			player.Bullets = append(player.Bullets, bullet)
+		} else {
+			world.EnemyTankBullets = append(world.EnemyTankBullets, bullet)
+		}
+	}
+}
+
+func (bench *Benchmark) Step(dt float32) {
+	bench.World.Step(dt)
+	bench.shoot()
+}
@@ -0,0 +1,52 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestBenchmarkTanks(t *testing.T) {
+	for _, tanks := range []int{1, 2, 3, 10, 100} {
+		bench := NewBenchmark(tanks, 10, 1)
+		if len(bench.World.EnemyTanks) != tanks {
+			t.Errorf("NewBenchmark(%d tanks) made %d tanks", tanks, len(bench.World.EnemyTanks))
+		}
+	}
+}
+
+// go test -bench . ./game
+func BenchmarkWorldStep(b *testing.B) {
+	for _, size := range []struct{ tanks, bullets int }{{10, 100}, {100, 1000}, {1000, 5000}} {
+		b.Run(fmt.Sprintf("%dtanks-%dbullets", size.tanks, size.bullets), func(b *testing.B) {
+			bench := NewBenchmark(size.tanks, size.bullets, 1)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				bench.Step(1.0 / 60.0)
+			}
+		})
+	}
+}
+
+func BenchmarkSpatialHash(b *testing.B) {
+	const objects = 1000
+	r := rand.New(rand.NewSource(1))
+	hash := NewSpatialHash(2000, 2000, SPATIAL_CELL_SIZE)
+	bounds := make([]Rect, objects)
+	for id := range bounds {
+		bounds[id] = Rect{X: GetRandomFloat32(0, 1940, r), Y: GetRandomFloat32(0, 1940, r), W: 60, H: 60}
+		hash.Insert(id, bounds[id])
+	}
+	var found []int
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// every object moves a little, and looks for it's neighbours, as the tanks do on a step
+		id := i % objects
+		moved := bounds[id]
+		moved.X = clamp(moved.X+GetRandomFloat32(-5, 5, r), 0, 1940)
+		moved.Y = clamp(moved.Y+GetRandomFloat32(-5, 5, r), 0, 1940)
+		hash.Move(id, bounds[id], moved)
+		bounds[id] = moved
+		found = hash.Query(moved, found[:0])
+	}
+}
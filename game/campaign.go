@@ -0,0 +1,156 @@
+// campaign.go
+package game
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+/*
+
+A campaign is a list of levels, played one after another(see resources/campaign.json).
+Every level has it's own map, and it's own enemy tanks.
+
+*/
+
+type LevelSettings struct {
+	Name                     string  `json:"name"`
+	Map                      string  `json:"map"`                      // path of the level file
+	MaxNumOfEnemyTanks       int     `json:"maxNumOfEnemyTanks"`       // the player wins, after destroying these many enemy tanks
+	EnemySpawnOffTime        float32 `json:"enemySpawnOffTime"`        // seconds
+	EnemyTankVelocity        float32 `json:"enemyTankVelocity"`        // pixels per second
+	EnemyTankMinNoUpdateTime float32 `json:"enemyTankMinNoUpdateTime"` // seconds, the enemy tanks do something, after a random time in this range
+	EnemyTankMaxNoUpdateTime float32 `json:"enemyTankMaxNoUpdateTime"` // seconds
+	CrazyTankChance          float32 `json:"crazyTankChance"`          // 0 to 1, the chance of a new enemy tank being a crazy tank(it does something almost on every frame)
+	EnemyTankHealth          int     `json:"enemyTankHealth"`          // number of player bullets, an enemy tank can take(0 means 1)
+
+	// weights of the behaviours of the enemy tanks(see BEHAVIOURS in ai.go),
+	// {"chase": 1, "random": 3} makes every fourth enemy tank a chasing one, empty means only random enemy tanks
+	EnemyBehaviours map[string]float32 `json:"enemyBehaviours"`
+
+	// weights of the classes of the enemy tanks(see ENEMY_CLASSES in enemy.go), like the behaviours, empty means only basic enemy tanks
+	EnemyClasses map[string]float32 `json:"enemyClasses"`
+
+	PowerUpChance float32 `json:"powerUpChance"` // 0 to 1, the chance of a destroyed enemy tank dropping a power-up(see powerup.go)
+
+	// weights of the power-ups(see POWER_UP_NAMES in powerup.go), like the behaviours, empty means every power-up equally
+	PowerUps map[string]float32 `json:"powerUps"`
+}
+
+// the settings of the first level, before there were campaigns
+func DefaultLevelSettings() LevelSettings {
+	return LevelSettings{
+		Name:                     "Level 0",
+		MaxNumOfEnemyTanks:       10,
+		EnemySpawnOffTime:        3.0,
+		EnemyTankVelocity:        310,
+		EnemyTankMinNoUpdateTime: 1.0,
+		EnemyTankMaxNoUpdateTime: 3.0,
+		CrazyTankChance:          0.0,
+	}
+}
+
+func (settings LevelSettings) Validate() error {
+	if err := CheckFinite(settings); err != nil {
+		return err
+	}
+	if settings.MaxNumOfEnemyTanks < 2 {
+		return errors.New("maxNumOfEnemyTanks must be at least 2")
+	}
+	if settings.EnemySpawnOffTime <= 0.0 {
+		return errors.New("enemySpawnOffTime must be positive")
+	}
+	if settings.EnemyTankVelocity < 0.0 {
+		return errors.New("enemyTankVelocity can not be negative")
+	}
+	if (settings.EnemyTankMinNoUpdateTime < 0.0) || (settings.EnemyTankMaxNoUpdateTime < settings.EnemyTankMinNoUpdateTime) {
+		return errors.New("enemyTankMinNoUpdateTime and enemyTankMaxNoUpdateTime must be a valid range")
+	}
+	if (settings.CrazyTankChance < 0.0) || (settings.CrazyTankChance > 1.0) {
+		return errors.New("crazyTankChance must be between 0 and 1")
+	}
+	if settings.EnemyTankHealth < 0 {
+		return errors.New("enemyTankHealth can not be negative")
+	}
+	for name, weight := range settings.EnemyBehaviours {
+		if _, ok := BEHAVIOURS[name]; !ok {
+			return fmt.Errorf("unknown enemy behaviour %q", name)
+		}
+		if !IsFinite(weight) || (weight < 0.0) {
+			return fmt.Errorf("the weight of the enemy behaviour %q must be a finite number, not negative", name)
+		}
+	}
+	for name, weight := range settings.EnemyClasses {
+		if _, err := FindEnemyClass(name); err != nil {
+			return err
+		}
+		if !IsFinite(weight) || (weight < 0.0) {
+			return fmt.Errorf("the weight of the enemy class %q must be a finite number, not negative", name)
+		}
+	}
+	if (settings.PowerUpChance < 0.0) || (settings.PowerUpChance > 1.0) {
+		return errors.New("powerUpChance must be between 0 and 1")
+	}
+	for name, weight := range settings.PowerUps {
+		if _, err := FindPowerUp(name); err != nil {
+			return err
+		}
+		if !IsFinite(weight) || (weight < 0.0) {
+			return fmt.Errorf("the weight of the power-up %q must be a finite number, not negative", name)
+		}
+	}
+	return nil
+}
+
+type Campaign struct {
+	Levels []LevelSettings `json:"levels"`
+}
+
+func ParseCampaign(r io.Reader) (*Campaign, error) {
+	campaign := &Campaign{}
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(campaign); err != nil {
+		return nil, err
+	}
+	if len(campaign.Levels) == 0 {
+		return nil, errors.New("the campaign has no levels")
+	}
+	for index, settings := range campaign.Levels {
+		if err := settings.Validate(); err != nil {
+			return nil, fmt.Errorf("level %d(%s): %v", index+1, settings.Name, err)
+		}
+	}
+	return campaign, nil
+}
+
+/*Identifies the levels of the campaign with their maps(the data of the map file of every level, in order),
+a replay is played only with the levels it was recorded with(see Replay.Levels).
+The paths of the maps are left out, the same map in another file is the same level.*/
+func (campaign *Campaign) Hash(maps [][]byte) ([sha256.Size]byte, error) {
+	if len(maps) != len(campaign.Levels) {
+		return [sha256.Size]byte{}, fmt.Errorf("expected the maps of %d levels, got %d", len(campaign.Levels), len(maps))
+	}
+	hash := sha256.New()
+	levels := make([]LevelSettings, len(campaign.Levels))
+	copy(levels, campaign.Levels)
+	for index := range levels {
+		levels[index].Map = ""
+	}
+	settings, err := json.Marshal(levels) // the keys of the maps in the settings are sorted, so it is always the same
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	// every part is written with it's length, so the parts can not run into each other
+	for _, part := range append([][]byte{settings}, maps...) {
+		binary.Write(hash, binary.LittleEndian, uint64(len(part)))
+		hash.Write(part)
+	}
+	var result [sha256.Size]byte
+	copy(result[:], hash.Sum(nil))
+	return result, nil
+}
@@ -0,0 +1,114 @@
+package game
+
+import (
+	"crypto/sha256"
+	"strings"
+	"testing"
+)
+
+const TEST_CAMPAIGN string = `{"levels": [
+	{"name": "One", "map": "one.txt", "maxNumOfEnemyTanks": 4, "enemySpawnOffTime": 2, "enemyTankVelocity": 200,
+		"enemyTankMinNoUpdateTime": 1, "enemyTankMaxNoUpdateTime": 2, "enemyBehaviours": {"chase": 1, "random": 3}},
+	{"name": "Two", "map": "two.txt", "maxNumOfEnemyTanks": 6, "enemySpawnOffTime": 1, "enemyTankVelocity": 250,
+		"enemyTankMinNoUpdateTime": 0.5, "enemyTankMaxNoUpdateTime": 1, "enemyClasses": {"basic": 2, "boss": 1}}
+]}`
+
+func TestParseCampaign(t *testing.T) {
+	campaign, err := ParseCampaign(strings.NewReader(TEST_CAMPAIGN))
+	if err != nil {
+		t.Fatalf("failed to parse the campaign: %v", err)
+	}
+	if (len(campaign.Levels) != 2) || (campaign.Levels[1].Name != "Two") || (campaign.Levels[1].EnemyClasses["boss"] != 1) {
+		t.Errorf("parsed %+v", campaign.Levels)
+	}
+}
+
+func TestInvalidCampaigns(t *testing.T) {
+	// one valid level, with this in it
+	level := func(settings string) string {
+		return `{"levels": [{"name": "Bad", "maxNumOfEnemyTanks": 4, "enemySpawnOffTime": 2, "enemyTankMaxNoUpdateTime": 1` + settings + `}]}`
+	}
+	tests := []struct {
+		name     string
+		campaign string
+		error    string
+	}{
+		{"not json", `levels:`, "invalid character"},
+		{"no levels", `{"levels": []}`, "no levels"},
+		{"unknown field", `{"levels": [], "bonusLevels": []}`, `unknown field "bonusLevels"`},
+		{"unknown field of a level", level(`, "enemyTankSpeed": 100`), `unknown field "enemyTankSpeed"`},
+		{"too few enemy tanks", level(`, "maxNumOfEnemyTanks": 1`), "level 1(Bad): maxNumOfEnemyTanks"},
+		{"no spawning", level(`, "enemySpawnOffTime": 0`), "enemySpawnOffTime"},
+		{"backwards time range", level(`, "enemyTankMinNoUpdateTime": 2`), "enemyTankMinNoUpdateTime"},
+		{"chance over 1", level(`, "crazyTankChance": 1.5`), "crazyTankChance"},
+		{"unknown behaviour", level(`, "enemyBehaviours": {"sleep": 1}`), `unknown enemy behaviour "sleep"`},
+		{"negative weight", level(`, "enemyBehaviours": {"chase": -1}`), `"chase"`},
+		{"unknown enemy class", level(`, "enemyClasses": {"dragon": 1}`), "dragon"},
+		{"unknown power-up", level(`, "powerUps": {"nuke": 1}`), "nuke"},
+	}
+	for _, test := range tests {
+		_, err := ParseCampaign(strings.NewReader(test.campaign))
+		if (err == nil) || !strings.Contains(err.Error(), test.error) {
+			t.Errorf("%s: error %v, expected %q", test.name, err, test.error)
+		}
+	}
+	if _, err := ParseCampaign(strings.NewReader(level(""))); err != nil {
+		t.Errorf("the level without changes should be valid: %v", err)
+	}
+}
+
+func TestCampaignHash(t *testing.T) {
+	parse := func() *Campaign {
+		campaign, err := ParseCampaign(strings.NewReader(TEST_CAMPAIGN))
+		if err != nil {
+			t.Fatalf("failed to parse the campaign: %v", err)
+		}
+		return campaign
+	}
+	maps := func() [][]byte {
+		return [][]byte{[]byte(levelText("BBBB")), []byte(levelText("SSSS"))}
+	}
+	hash := func(campaign *Campaign, maps [][]byte) [sha256.Size]byte {
+		result, err := campaign.Hash(maps)
+		if err != nil {
+			t.Fatalf("failed to hash the campaign: %v", err)
+		}
+		return result
+	}
+	original := hash(parse(), maps())
+	if hash(parse(), maps()) != original {
+		t.Fatal("the same campaign has another hash")
+	}
+
+	changed := maps()
+	changed[1][3] = 'b' // one damaged wall
+	if hash(parse(), changed) == original {
+		t.Error("the hash did not change, when a byte of a map changed")
+	}
+	swapped := maps()
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+	if hash(parse(), swapped) == original {
+		t.Error("the hash did not change, when the maps were swapped")
+	}
+	// the data of the maps is the same, only split differently between them
+	shifted := maps()
+	shifted[0], shifted[1] = append(shifted[0], shifted[1][0]), shifted[1][1:]
+	if hash(parse(), shifted) == original {
+		t.Error("the hash did not change, when a byte moved from one map to the other")
+	}
+
+	settings := parse()
+	settings.Levels[0].EnemyBehaviours["chase"] = 2
+	if hash(settings, maps()) == original {
+		t.Error("the hash did not change, when the settings of a level changed")
+	}
+	moved := parse()
+	moved.Levels[0].Map = "levels/one.txt"
+	if hash(moved, maps()) != original {
+		t.Error("the hash changed with the path of a map, the same map in another file is the same level")
+	}
+
+	if _, err := parse().Hash(maps()[:1]); err == nil {
+		t.Error("hashed a campaign of 2 levels with 1 map")
+	}
+}
@@ -0,0 +1,171 @@
+// controller.go
+package game
+
+/*
+
+A Controller decides what a tank wants to do, in every step of the world.
+The world asks the controller of every tank for an Intent, and then moves, turns and shoots
+the tank by that intent, so the world does not care who controls a tank:
+the keyboard and the gamepads(InputController, fed by the renderer), a replay(ReplayController, see replay.go),
+the AI(AIController, see ai.go) or a script(ScriptedController).
+
+New ways of controlling tanks(network...) only need a new Controller.
+
+*/
+
+// Intent is what a tank wants to do in one step
+type Intent struct {
+	MoveX    float32 // -1(left) to 1(right), multiplied by the velocity of the tank
+	MoveY    float32 // -1(up) to 1(down)
+	Rotate   float32 // -1(anti clock wise) to 1(clock wise), multiplied by the rotation speed of the tank
+	Aim      bool    // turn towards AimAngle, the shorter way
+	AimAngle float32 // degrees
+	Fire     bool
+
+	// tank controls(see Rules.TankControls)
+	Drive  float32 // -1(backward) to 1(forward), along the heading of the tank, the tank speeds up and slows down(see Tank.Accelerate)
+	Steer  float32 // -1(anti clock wise) to 1(clock wise), turns the hull
+	Turret bool    // Rotate and Aim turn only the turret, not the whole tank
+}
+
+type Controller interface {
+	Update(world *World, tank *Tank, dt float32) Intent
+}
+
+func (input Input) Intent() Intent {
+	var intent Intent
+	if input.MoveLeft {
+		intent.MoveX -= 1.0
+	}
+	if input.MoveRight {
+		intent.MoveX += 1.0
+	}
+	if input.MoveUp {
+		intent.MoveY -= 1.0
+	}
+	if input.MoveDown {
+		intent.MoveY += 1.0
+	}
+	if input.RotateAntiClockWise {
+		intent.Rotate -= 1.0
+	}
+	if input.RotateClockWise {
+		intent.Rotate += 1.0
+	}
+	intent.MoveX = clamp(intent.MoveX+(float32(input.MoveX)/INPUT_AXIS_MAX), -1.0, 1.0)
+	intent.MoveY = clamp(intent.MoveY+(float32(input.MoveY)/INPUT_AXIS_MAX), -1.0, 1.0)
+	if input.Aim {
+		intent.Aim = true
+		intent.AimAngle = float32(input.AimAngle) * (360.0 / INPUT_AIM_STEPS)
+	}
+	intent.Fire = input.Shoot
+	return intent
+}
+
+// with tank controls: forward and backward drive, left and right steer, and the rotate keys turn the turret
+func (input Input) TankIntent() Intent {
+	var intent Intent
+	if input.MoveUp {
+		intent.Drive += 1.0
+	}
+	if input.MoveDown {
+		intent.Drive -= 1.0
+	}
+	if input.MoveLeft {
+		intent.Steer -= 1.0
+	}
+	if input.MoveRight {
+		intent.Steer += 1.0
+	}
+	if input.RotateAntiClockWise {
+		intent.Rotate -= 1.0
+	}
+	if input.RotateClockWise {
+		intent.Rotate += 1.0
+	}
+	intent.Drive = clamp(intent.Drive-(float32(input.MoveY)/INPUT_AXIS_MAX), -1.0, 1.0) // the stick up is forward
+	intent.Steer = clamp(intent.Steer+(float32(input.MoveX)/INPUT_AXIS_MAX), -1.0, 1.0)
+	if input.Aim {
+		intent.Aim = true
+		intent.AimAngle = float32(input.AimAngle) * (360.0 / INPUT_AIM_STEPS)
+	}
+	intent.Fire = input.Shoot
+	intent.Turret = true
+	return intent
+}
+
+// the intent of the input, by the controls of the world(see Rules.TankControls)
+func (world *World) intentOf(input Input) Intent {
+	if world.config.Rules.TankControls {
+		return input.TankIntent()
+	}
+	return input.Intent()
+}
+
+func clamp(value float32, min float32, max float32) float32 {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
+//==============INPUT==============
+
+// InputController controls a tank by the Input, set before every step(by the keyboard, for example)
+type InputController struct {
+	Input Input
+}
+
+func (controller *InputController) Update(world *World, tank *Tank, dt float32) Intent {
+	return world.intentOf(controller.Input)
+}
+
+//==============AI==============
+
+// AIController lets a Behaviour think, every noUpdateTime seconds
+type AIController struct {
+	Behaviour    Behaviour
+	noUpdateTime float32
+	timer        float32 // simulated seconds, since the last think
+}
+
+func NewAIController(behaviour Behaviour, noUpdateTime float32) *AIController {
+	return &AIController{
+		Behaviour:    behaviour,
+		noUpdateTime: noUpdateTime,
+	}
+}
+
+func (controller *AIController) Update(world *World, tank *Tank, dt float32) Intent {
+	return controller.Behaviour.Update(world, tank, controller.WillUpdate(dt))
+}
+
+/*This function seems to be very innocent, not mutating the receiver.
+Actually, it changes the timer of the receiver. Be careful...*/
+func (controller *AIController) WillUpdate(delta float32) bool {
+	controller.timer += delta
+	if controller.timer >= controller.noUpdateTime {
+		controller.timer = 0.0
+		return true
+	}
+	return false
+}
+
+//==============SCRIPTED==============
+
+// ScriptedController plays a list of intents, one on every step, and then stands still(for demos, tests and bots)
+type ScriptedController struct {
+	Intents []Intent
+	step    int
+}
+
+func (controller *ScriptedController) Update(world *World, tank *Tank, dt float32) Intent {
+	if controller.step >= len(controller.Intents) {
+		return Intent{}
+	}
+	controller.step += 1
+	return controller.Intents[controller.step-1]
+}
@@ -0,0 +1,70 @@
+// enemy.go
+package game
+
+import (
+	"fmt"
+)
+
+/*
+
+Every enemy tank belongs to a class: how much it can take, how fast it is, what it shoots with, how it thinks,
+how many points it is worth and how it looks. The classes are in ENEMY_CLASSES, the levels pick which classes
+their enemy tanks are by weights(see LevelSettings.EnemyClasses), like the behaviours.
+
+The zero values mean "as the level says": a class without Health has the health of the level(LevelSettings.EnemyTankHealth),
+without a Behaviour it gets one of the behaviours of the level, and so on. So the basic class is the enemy tank of
+the level, as it always was, and levels without classes play as before.
+
+*/
+
+type EnemyClass struct {
+	Name         string
+	Health       int      // number of player bullets, the tank can take, 0 means LevelSettings.EnemyTankHealth
+	Speed        float32  // times LevelSettings.EnemyTankVelocity, 0 means 1
+	ReactionTime float32  // times the think time of the tank(smaller thinks faster), 0 means 1
+	Weapon       string   // one of WEAPONS, empty means Rules.EnemyWeapon
+	Behaviour    string   // one of BEHAVIOURS, empty means one of LevelSettings.EnemyBehaviours
+	Score        int      // for destroying it, 0 means Rules.EnemyTankScore
+	Scale        float32  // of the size of the tank, 0 means 1
+	Tint         [3]uint8 // red, green and blue, multiplied with the texture, zero means no tint
+}
+
+const ENEMY_CLASS_BASIC string = "basic"
+
+var ENEMY_CLASSES []EnemyClass = []EnemyClass{
+	{Name: ENEMY_CLASS_BASIC},
+	{Name: "light", Health: 1, Speed: 1.4, ReactionTime: 0.7, Score: 150, Scale: 0.85, Tint: [3]uint8{255, 235, 140}},
+	{Name: "armored", Health: 4, Speed: 0.7, Score: 300, Tint: [3]uint8{150, 170, 150}},
+	{Name: "artillery", Health: 2, Speed: 0.6, Weapon: "artillery", Behaviour: BEHAVIOUR_ARTILLERY, Score: 250, Tint: [3]uint8{200, 170, 255}},
+	{Name: "boss", Health: 12, Speed: 0.6, ReactionTime: 0.5, Weapon: "missile", Behaviour: BEHAVIOUR_CHASE, Score: 1000, Scale: 1.4, Tint: [3]uint8{255, 120, 120}},
+}
+
+func FindEnemyClass(name string) (*EnemyClass, error) {
+	for index := range ENEMY_CLASSES {
+		if ENEMY_CLASSES[index].Name == name {
+			return &ENEMY_CLASSES[index], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown enemy class %q", name)
+}
+
+// picks a class by the weights of the level, the basic class if there are no weights
+func (world *World) newEnemyClass() *EnemyClass {
+	name, weighted := world.pickByWeight(world.config.LevelSettings.EnemyClasses)
+	if !weighted {
+		name = ENEMY_CLASS_BASIC
+	}
+	class, err := FindEnemyClass(name)
+	if err != nil { // the level settings have been validated
+		class, _ = FindEnemyClass(ENEMY_CLASS_BASIC)
+	}
+	return class
+}
+
+// points for destroying the enemy tank
+func (world *World) enemyScore(tank *Tank) int {
+	if (tank.Class != nil) && (tank.Class.Score > 0) {
+		return tank.Class.Score
+	}
+	return world.config.Rules.EnemyTankScore
+}
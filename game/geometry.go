@@ -0,0 +1,168 @@
+// geometry.go
+package game
+
+import (
+	"math"
+)
+
+/*
+
+Plain geometry, so that the game rules do not depend on sdl.
+Rect and Point have exactly the same fields as sdl.FRect and sdl.FPoint,
+so the renderer can simply convert them: sdl.FRect(rect)
+
+The tanks are drawn rotated, so they collide as rotated rectangles(OBB), the axis aligned Rects
+are only their unrotated boxes(and the bounds for the broadphase).
+
+*/
+
+type Point struct {
+	X float32
+	Y float32
+}
+
+type Rect struct {
+	X float32
+	Y float32
+	W float32
+	H float32
+}
+
+func (p Point) InRect(r Rect) bool {
+	return (p.X >= r.X) && (p.X < (r.X + r.W)) &&
+		(p.Y >= r.Y) && (p.Y < (r.Y + r.H))
+}
+
+func (a Rect) Empty() bool {
+	return a.W <= 0 || a.H <= 0
+}
+
+// same as sdl.FRect.HasIntersection
+func (a Rect) HasIntersection(b Rect) bool {
+	if a.Empty() || b.Empty() {
+		return false
+	}
+	if a.X >= b.X+b.W || a.X+a.W <= b.X || a.Y >= b.Y+b.H || a.Y+a.H <= b.Y {
+		return false
+	}
+	return true
+}
+
+func (a Rect) Centre() Point {
+	return Point{
+		X: a.X + (a.W / 2.0),
+		Y: a.Y + (a.H / 2.0),
+	}
+}
+
+func (a Rect) Size() Size {
+	return Size{a.W, a.H}
+}
+
+// Size is the width and height of a game object, usually the size of it's texture
+type Size struct {
+	W float32
+	H float32
+}
+
+//==============ORIENTED BOUNDING BOXES==============
+
+/*OBB is a rectangle, rotated around it's centre, like the textures are drawn(see sdl.Renderer.CopyExF),
+so the tanks collide with exactly what is seen on the screen.
+The angle is in degrees, clockwise(the y axis points down), same as Tank.RotationAngle.*/
+type OBB struct {
+	Centre Point
+	HalfW  float32
+	HalfH  float32
+	axisX  Point // unit vector along the width(the heading of a tank)
+	axisY  Point // unit vector along the height
+}
+
+func NewOBB(box Rect, angle float32) OBB {
+	radians := DegreeToRadian(float64(angle))
+	cos, sin := float32(math.Cos(radians)), float32(math.Sin(radians))
+	return OBB{
+		Centre: box.Centre(),
+		HalfW:  box.W / 2.0,
+		HalfH:  box.H / 2.0,
+		axisX:  Point{cos, sin},
+		axisY:  Point{-sin, cos},
+	}
+}
+
+// the smallest axis aligned rectangle around the box(for the broadphase, see spatial.go)
+func (obb OBB) Bounds() Rect {
+	extentX := (abs(obb.axisX.X) * obb.HalfW) + (abs(obb.axisY.X) * obb.HalfH)
+	extentY := (abs(obb.axisX.Y) * obb.HalfW) + (abs(obb.axisY.Y) * obb.HalfH)
+	return Rect{
+		X: obb.Centre.X - extentX,
+		Y: obb.Centre.Y - extentY,
+		W: 2.0 * extentX,
+		H: 2.0 * extentY,
+	}
+}
+
+// half of the length of the shadow of the box, on the axis(a unit vector)
+func (obb OBB) radius(axis Point) float32 {
+	return (obb.HalfW * abs(dot(obb.axisX, axis))) + (obb.HalfH * abs(dot(obb.axisY, axis)))
+}
+
+/*The separating axis test: two convex shapes do not overlap, if their shadows do not overlap on one of the axes
+(for two rectangles, the axes of their sides are enough).
+Like Rect.HasIntersection, just touching boxes do not intersect.*/
+func (a OBB) Intersects(b OBB) bool {
+	if (a.HalfW <= 0.0) || (a.HalfH <= 0.0) || (b.HalfW <= 0.0) || (b.HalfH <= 0.0) {
+		return false
+	}
+	between := Point{b.Centre.X - a.Centre.X, b.Centre.Y - a.Centre.Y}
+	for _, axis := range [4]Point{a.axisX, a.axisY, b.axisX, b.axisY} {
+		if abs(dot(between, axis)) >= (a.radius(axis) + b.radius(axis)) {
+			return false
+		}
+	}
+	return true
+}
+
+/*The segment from one point to the other crosses(or is inside of) the box.
+Used for the bullets, with the positions of their noses before and after a step, so that a fast bullet
+can not jump over a tank between two steps.*/
+func (obb OBB) IntersectsSegment(from Point, to Point) bool {
+	// in the space of the box, where it is an axis aligned rectangle around (0, 0)
+	offset := Point{from.X - obb.Centre.X, from.Y - obb.Centre.Y}
+	delta := Point{to.X - from.X, to.Y - from.Y}
+	enter, exit := float32(0.0), float32(1.0)
+	for _, slab := range [2]struct {
+		axis Point
+		half float32
+	}{{obb.axisX, obb.HalfW}, {obb.axisY, obb.HalfH}} {
+		start, length := dot(offset, slab.axis), dot(delta, slab.axis)
+		if length == 0.0 { // parallel to the slab
+			if abs(start) >= slab.half {
+				return false
+			}
+			continue
+		}
+		near, far := (-slab.half-start)/length, (slab.half-start)/length
+		if near > far {
+			near, far = far, near
+		}
+		if near > enter {
+			enter = near
+		}
+		if far < exit {
+			exit = far
+		}
+		if enter > exit {
+			return false
+		}
+	}
+	return true
+}
+
+func dot(a Point, b Point) float32 {
+	return (a.X * b.X) + (a.Y * b.Y)
+}
+
+func abs(value float32) float32 {
+	return float32(math.Abs(float64(value)))
+}
@@ -0,0 +1,107 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+// a 60x60 box, this far from the one at (0, 0) along the diagonal(rotated by 45 degrees,
+// their facing sides touch at 60 pixels)
+func diagonal(distance float32) Rect {
+	offset := distance / float32(math.Sqrt2)
+	return Rect{X: offset, Y: offset, W: 60, H: 60}
+}
+
+func TestOBBIntersects(t *testing.T) {
+	box := Rect{X: 0, Y: 0, W: 60, H: 60}
+	cornerReach := float32(30 * math.Sqrt2) // from the centre to a corner of a 60x60 box
+	tests := []struct {
+		name   string
+		a      OBB
+		b      OBB
+		result bool
+	}{
+		{"overlapping", NewOBB(box, 0), NewOBB(Rect{X: 30, Y: 30, W: 60, H: 60}, 0), true},
+		{"same box", NewOBB(box, 30), NewOBB(box, 30), true},
+		{"touching edges", NewOBB(box, 0), NewOBB(Rect{X: 60, Y: 0, W: 60, H: 60}, 0), false},
+		{"touching rotated edges", NewOBB(Rect{W: 60, H: 60}, 45), NewOBB(diagonal(60), 45), false},
+		{"apart", NewOBB(box, 0), NewOBB(Rect{X: 200, Y: 0, W: 60, H: 60}, 0), false},
+		// the corner of the rotated box pokes 5 pixels into the other one
+		{"rotated corner inside", NewOBB(box, 0), NewOBB(Rect{X: 60 + cornerReach - 5 - 30, Y: 0, W: 60, H: 60}, 45), true},
+		{"rotated corner outside", NewOBB(box, 0), NewOBB(Rect{X: 60 + cornerReach + 2 - 30, Y: 0, W: 60, H: 60}, 45), false},
+		// the bounds of these overlap, but the boxes themselves do not
+		{"rotated near miss", NewOBB(Rect{W: 60, H: 60}, 45), NewOBB(diagonal(62), 45), false},
+		{"rotated overlap", NewOBB(Rect{W: 60, H: 60}, 45), NewOBB(diagonal(58), 45), true},
+		{"long and thin, crossing", NewOBB(Rect{X: 0, Y: 45, W: 100, H: 10}, 30), NewOBB(Rect{X: 0, Y: 45, W: 100, H: 10}, -30), true},
+		{"empty box", NewOBB(box, 0), NewOBB(Rect{X: 10, Y: 10, W: 0, H: 0}, 0), false},
+	}
+	for _, test := range tests {
+		if result := test.a.Intersects(test.b); result != test.result {
+			t.Errorf("%s: Intersects is %v, expected %v", test.name, result, test.result)
+		}
+		if result := test.b.Intersects(test.a); result != test.result {
+			t.Errorf("%s(the other way around): Intersects is %v, expected %v", test.name, result, test.result)
+		}
+	}
+	if near, far := NewOBB(Rect{W: 60, H: 60}, 45), NewOBB(diagonal(62), 45); !near.Bounds().HasIntersection(far.Bounds()) {
+		t.Error("rotated near miss: the bounds should overlap, otherwise the test does not test the rotation")
+	}
+}
+
+func TestOBBIntersectsSegment(t *testing.T) {
+	box := NewOBB(Rect{X: 100, Y: 100, W: 60, H: 60}, 0)
+	rotated := NewOBB(Rect{X: 100, Y: 100, W: 60, H: 60}, 45)
+	tests := []struct {
+		name     string
+		obb      OBB
+		from, to Point
+		result   bool
+	}{
+		{"through the centre", box, Point{50, 130}, Point{210, 130}, true},
+		{"inside", box, Point{120, 120}, Point{140, 140}, true},
+		{"ending inside", box, Point{50, 130}, Point{110, 130}, true},
+		{"ending before the edge", box, Point{50, 130}, Point{99, 130}, false},
+		{"starting after the edge", box, Point{161, 130}, Point{250, 130}, false},
+		{"parallel, outside", box, Point{50, 90}, Point{210, 90}, false},
+		{"along the edge", box, Point{50, 100}, Point{210, 100}, false},
+		{"diagonal", box, Point{90, 90}, Point{170, 170}, true},
+		{"not moving, inside", box, Point{130, 130}, Point{130, 130}, true},
+		{"not moving, outside", box, Point{90, 130}, Point{90, 130}, false},
+		// through a corner of the bounds of the rotated box, which is not a part of the box
+		{"rotated, through a corner of the bounds", rotated, Point{95, 105}, Point{105, 95}, false},
+		{"rotated, through the centre", rotated, Point{80, 80}, Point{180, 180}, true},
+	}
+	for _, test := range tests {
+		if result := test.obb.IntersectsSegment(test.from, test.to); result != test.result {
+			t.Errorf("%s: IntersectsSegment is %v, expected %v", test.name, result, test.result)
+		}
+	}
+}
+
+// a bullet fast enough to cross a whole tank in one step still hits it
+func TestFastBulletHits(t *testing.T) {
+	hull := NewOBB(Rect{X: 200, Y: 200, W: DEFAULT_TANK_WIDTH, H: DEFAULT_TANK_HEIGHT}, 30)
+	bullet := Bullet{
+		Velocity:    12000, // 200 pixels in a step at 60 steps per second
+		BoundingBox: Rect{X: 100, Y: 225, W: DEFAULT_BULLET_WIDTH, H: DEFAULT_BULLET_HEIGHT},
+	}
+	bullet.Update(1.0 / 60.0)
+	before, after := bullet.lastNose, bullet.NosePosition()
+	if hull.IntersectsSegment(before, before) || hull.IntersectsSegment(after, after) {
+		t.Fatalf("the nose should be outside of the tank, before(%v) and after(%v) the step", before, after)
+	}
+	if !bullet.Hits(hull) {
+		t.Errorf("the bullet flew through the tank from %v to %v, without hitting it", before, after)
+	}
+
+	// and it is found by the broadphase, by it's swept bounds(see spatial.go)
+	grid := bulletGrid{hash: NewSpatialHash(DEFAULT_ARENA_WIDTH, DEFAULT_ARENA_HEIGHT, SPATIAL_CELL_SIZE)}
+	bullets := []Bullet{bullet}
+	grid.reset(&bullets)
+	if _, hit := grid.removeHitting(hull); !hit {
+		t.Error("the bullet grid did not find the fast bullet")
+	}
+	if len(bullets) != 0 {
+		t.Errorf("the bullet should be used up, %d bullets left", len(bullets))
+	}
+}
@@ -0,0 +1,246 @@
+// level.go
+package game
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+/*
+
+A level is a grid of tiles, the level files are plain text, one character per tile,
+and one line per row of tiles(see resources/levels/level_0.txt).
+Lines starting with '#' are comments.
+
+Brick and steel walls can be destroyed by bullets, one tile at a time. The health of every tile
+is a part of the level, a damaged tile is written in lower case(see Level.String), so a level
+can be saved and loaded again, with it's damaged walls.
+
+*/
+
+type Tile byte
+
+const (
+	TILE_EMPTY Tile = iota
+	TILE_BRICK
+	TILE_STEEL
+	TILE_WATER
+	TILE_TREES
+)
+
+var TILE_CHARACTERS map[rune]Tile = map[rune]Tile{
+	'.': TILE_EMPTY,
+	'B': TILE_BRICK,
+	'S': TILE_STEEL,
+	'W': TILE_WATER,
+	'T': TILE_TREES,
+}
+
+// damaged tiles(with 1 health left)
+var DAMAGED_TILE_CHARACTERS map[rune]Tile = map[rune]Tile{
+	'b': TILE_BRICK,
+	's': TILE_STEEL,
+}
+
+// number of hits, a tile can take, 0 means indestructible
+func (tile Tile) MaxHealth() int {
+	switch tile {
+	case TILE_BRICK:
+		return BRICK_HEALTH
+	case TILE_STEEL:
+		return STEEL_HEALTH
+	}
+	return 0
+}
+
+// tanks can not cross these tiles
+func (tile Tile) BlocksTank() bool {
+	return tile == TILE_BRICK || tile == TILE_STEEL || tile == TILE_WATER
+}
+
+// bullets stop on these tiles
+func (tile Tile) BlocksBullet() bool {
+	return tile == TILE_BRICK || tile == TILE_STEEL
+}
+
+type Level struct {
+	Columns  int
+	Rows     int
+	TileSize float32
+	Tiles    []Tile // row by row
+	Health   []int  // health of every tile, row by row
+}
+
+func NewEmptyLevel() *Level {
+	return &Level{
+		Columns:  LEVEL_COLUMNS,
+		Rows:     LEVEL_ROWS,
+		TileSize: TILE_SIZE,
+		Tiles:    make([]Tile, LEVEL_COLUMNS*LEVEL_ROWS),
+		Health:   make([]int, LEVEL_COLUMNS*LEVEL_ROWS),
+	}
+}
+
+// every world damages it's own copy of the level
+func (level *Level) Clone() *Level {
+	result := *level
+	result.Tiles = append([]Tile(nil), level.Tiles...)
+	result.Health = append([]int(nil), level.Health...)
+	return &result
+}
+
+func ParseLevel(r io.Reader) (*Level, error) {
+	level := &Level{
+		Columns:  LEVEL_COLUMNS,
+		Rows:     0,
+		TileSize: TILE_SIZE,
+	}
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber += 1
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if (line == "") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		row := []rune(line)
+		if len(row) != LEVEL_COLUMNS {
+			return nil, fmt.Errorf("line %d: expected %d tiles, found %d", lineNumber, LEVEL_COLUMNS, len(row))
+		}
+		for column, character := range row {
+			if tile, ok := TILE_CHARACTERS[character]; ok {
+				level.Tiles = append(level.Tiles, tile)
+				level.Health = append(level.Health, tile.MaxHealth())
+			} else if tile, ok := DAMAGED_TILE_CHARACTERS[character]; ok {
+				level.Tiles = append(level.Tiles, tile)
+				level.Health = append(level.Health, 1)
+			} else {
+				return nil, fmt.Errorf("line %d, column %d: unknown tile '%c'", lineNumber, column+1, character)
+			}
+		}
+		level.Rows += 1
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if level.Rows != LEVEL_ROWS {
+		return nil, fmt.Errorf("expected %d rows of tiles, found %d", LEVEL_ROWS, level.Rows)
+	}
+	return level, nil
+}
+
+// outside of the level, everything is empty
+func (level *Level) TileAt(column int, row int) Tile {
+	if (column < 0) || (row < 0) || (column >= level.Columns) || (row >= level.Rows) {
+		return TILE_EMPTY
+	}
+	return level.Tiles[row*level.Columns+column]
+}
+
+func (level *Level) Damaged(column int, row int) bool {
+	tile := level.TileAt(column, row)
+	return (tile.MaxHealth() > 0) && (level.Health[row*level.Columns+column] < tile.MaxHealth())
+}
+
+/*Damages the tile, returns true if the tile has been destroyed.
+Steel walls are damaged only by heavy bullets.*/
+func (level *Level) DamageTile(column int, row int, power int) bool {
+	tile := level.TileAt(column, row)
+	if tile.MaxHealth() == 0 {
+		return false
+	}
+	if tile == TILE_STEEL {
+		if power < HEAVY_BULLET_POWER {
+			return false
+		}
+		power = 1 // one heavy bullet, one hit
+	}
+	index := row*level.Columns + column
+	level.Health[index] -= power
+	if level.Health[index] > 0 {
+		return false
+	}
+	level.Tiles[index] = TILE_EMPTY
+	level.Health[index] = 0
+	return true
+}
+
+// the same format, as of the level files(without the comments)
+func (level *Level) String() string {
+	var builder strings.Builder
+	for row := 0; row < level.Rows; row++ {
+		for column := 0; column < level.Columns; column++ {
+			builder.WriteRune(level.tileCharacter(column, row))
+		}
+		builder.WriteByte('\n')
+	}
+	return builder.String()
+}
+
+func (level *Level) tileCharacter(column int, row int) rune {
+	tile := level.TileAt(column, row)
+	characters := TILE_CHARACTERS
+	if level.Damaged(column, row) {
+		characters = DAMAGED_TILE_CHARACTERS
+	}
+	for character, t := range characters {
+		if t == tile {
+			return character
+		}
+	}
+	return '.'
+}
+
+func (level *Level) TileRect(column int, row int) Rect {
+	return Rect{
+		X: float32(column) * level.TileSize,
+		Y: float32(row) * level.TileSize,
+		W: level.TileSize,
+		H: level.TileSize,
+	}
+}
+
+// returns the column and row of the tile, under the point
+func (level *Level) Cell(point Point) (int, int) {
+	return int(math.Floor(float64(point.X / level.TileSize))), int(math.Floor(float64(point.Y / level.TileSize)))
+}
+
+// calls f for every tile, which overlaps with bounds(tiles just touching the bounds are not included)
+func (level *Level) forEachTileIn(bounds Rect, f func(column int, row int, tile Tile) bool) {
+	firstColumn, firstRow := level.Cell(Point{bounds.X, bounds.Y})
+	lastColumn := int(math.Ceil(float64((bounds.X+bounds.W)/level.TileSize))) - 1
+	lastRow := int(math.Ceil(float64((bounds.Y+bounds.H)/level.TileSize))) - 1
+	for row := firstRow; row <= lastRow; row++ {
+		for column := firstColumn; column <= lastColumn; column++ {
+			if !f(column, row, level.TileAt(column, row)) {
+				return
+			}
+		}
+	}
+}
+
+func (level *Level) BlocksTank(bounds Rect) bool {
+	blocked := false
+	level.forEachTileIn(bounds, func(column int, row int, tile Tile) bool {
+		blocked = tile.BlocksTank()
+		return !blocked
+	})
+	return blocked
+}
+
+// like BlocksTank, for a rotated tank
+func (level *Level) BlocksHull(hull OBB) bool {
+	blocked := false
+	level.forEachTileIn(hull.Bounds(), func(column int, row int, tile Tile) bool {
+		blocked = tile.BlocksTank() && hull.Intersects(NewOBB(level.TileRect(column, row), 0.0))
+		return !blocked
+	})
+	return blocked
+}
+
+func (level *Level) BlocksBullet(point Point) bool {
+	return level.TileAt(level.Cell(point)).BlocksBullet()
+}
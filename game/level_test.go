@@ -0,0 +1,216 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+// a level file with these rows at the top, the rest of the rows are empty
+func levelText(rows ...string) string {
+	var builder strings.Builder
+	for row := 0; row < LEVEL_ROWS; row++ {
+		if row < len(rows) {
+			builder.WriteString(rows[row])
+		} else {
+			builder.WriteString(strings.Repeat(".", LEVEL_COLUMNS))
+		}
+		builder.WriteByte('\n')
+	}
+	return builder.String()
+}
+
+func mustParseLevel(t *testing.T, text string) *Level {
+	t.Helper()
+	level, err := ParseLevel(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("failed to parse the level: %v", err)
+	}
+	return level
+}
+
+func TestParseLevelErrors(t *testing.T) {
+	row := strings.Repeat(".", LEVEL_COLUMNS)
+	tests := []struct {
+		name  string
+		text  string
+		error string
+	}{
+		{"short row", levelText(row[1:]), "line 1: expected 20 tiles, found 19"},
+		{"long row", levelText(row, row+"B"), "line 2: expected 20 tiles, found 21"},
+		{"missing rows", strings.Repeat(row+"\n", LEVEL_ROWS-1), "expected 20 rows of tiles, found 19"},
+		{"too many rows", levelText() + row + "\n", "expected 20 rows of tiles, found 21"},
+		{"unknown tile", levelText("...X" + row[4:]), "line 1, column 4: unknown tile 'X'"},
+		{"unknown damaged tile", levelText(row, "w"+row[1:]), "line 2, column 1: unknown tile 'w'"},
+		// the line numbers count the comments too
+		{"after a comment", "# a comment\n" + levelText("?"+row[1:]), "line 2, column 1: unknown tile '?'"},
+	}
+	for _, test := range tests {
+		_, err := ParseLevel(strings.NewReader(test.text))
+		if (err == nil) || !strings.Contains(err.Error(), test.error) {
+			t.Errorf("%s: error %v, expected %q", test.name, err, test.error)
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	level := mustParseLevel(t, "# comments and empty lines are skipped\n\n"+levelText(
+		"BSWT.bs.............",
+		"....................  \r", // trailing white space is trimmed
+	))
+	tests := []struct {
+		column int
+		tile   Tile
+		health int
+	}{
+		{0, TILE_BRICK, BRICK_HEALTH},
+		{1, TILE_STEEL, STEEL_HEALTH},
+		{2, TILE_WATER, 0},
+		{3, TILE_TREES, 0},
+		{4, TILE_EMPTY, 0},
+		{5, TILE_BRICK, 1},
+		{6, TILE_STEEL, 1},
+	}
+	for _, test := range tests {
+		if tile := level.TileAt(test.column, 0); tile != test.tile {
+			t.Errorf("column %d: tile %d, expected %d", test.column, tile, test.tile)
+		}
+		if health := level.Health[test.column]; health != test.health {
+			t.Errorf("column %d: health %d, expected %d", test.column, health, test.health)
+		}
+	}
+	if (level.Columns != LEVEL_COLUMNS) || (level.Rows != LEVEL_ROWS) || (len(level.Tiles) != LEVEL_COLUMNS*LEVEL_ROWS) {
+		t.Errorf("%dx%d level with %d tiles", level.Columns, level.Rows, len(level.Tiles))
+	}
+	if tile := level.TileAt(-1, 0); tile != TILE_EMPTY {
+		t.Errorf("tile %d outside of the level, expected it to be empty", tile)
+	}
+}
+
+func TestLevelStringRoundTrip(t *testing.T) {
+	text := levelText(
+		"BBBB....SSSS....WWWW",
+		"bbss....TTTT....BsbS",
+		"..........B.........",
+	)
+	level := mustParseLevel(t, text)
+	if level.String() != text {
+		t.Errorf("String is\n%s\nexpected\n%s", level.String(), text)
+	}
+	again := mustParseLevel(t, level.String())
+	if again.String() != level.String() {
+		t.Error("the level changed, when parsed again")
+	}
+	for index := range level.Tiles {
+		if (again.Tiles[index] != level.Tiles[index]) || (again.Health[index] != level.Health[index]) {
+			t.Errorf("tile %d: %d(health %d), expected %d(health %d)", index, again.Tiles[index], again.Health[index], level.Tiles[index], level.Health[index])
+		}
+	}
+}
+
+func TestTileBlocking(t *testing.T) {
+	tests := []struct {
+		tile   Tile
+		tank   bool
+		bullet bool
+	}{
+		{TILE_EMPTY, false, false},
+		{TILE_BRICK, true, true},
+		{TILE_STEEL, true, true},
+		{TILE_WATER, true, false}, // bullets fly over water
+		{TILE_TREES, false, false},
+	}
+	for _, test := range tests {
+		if test.tile.BlocksTank() != test.tank {
+			t.Errorf("tile %d: BlocksTank is %v, expected %v", test.tile, !test.tank, test.tank)
+		}
+		if test.tile.BlocksBullet() != test.bullet {
+			t.Errorf("tile %d: BlocksBullet is %v, expected %v", test.tile, !test.bullet, test.bullet)
+		}
+
+		// one tile at column 4, row 4
+		level := NewEmptyLevel()
+		level.Tiles[4*level.Columns+4] = test.tile
+		tile := level.TileRect(4, 4)
+		if blocked := level.BlocksTank(tile); blocked != test.tank {
+			t.Errorf("tile %d: BlocksTank of the tile is %v, expected %v", test.tile, blocked, test.tank)
+		}
+		if blocked := level.BlocksHull(NewOBB(Rect{X: tile.X - 20, Y: tile.Y - 20, W: 30, H: 30}, 0)); blocked != test.tank {
+			t.Errorf("tile %d: BlocksHull of an overlapping hull is %v, expected %v", test.tile, blocked, test.tank)
+		}
+		if blocked := level.BlocksBullet(tile.Centre()); blocked != test.bullet {
+			t.Errorf("tile %d: BlocksBullet at it's centre is %v, expected %v", test.tile, blocked, test.bullet)
+		}
+	}
+
+	// next to a wall, touching or with a rotated corner just missing it
+	level := NewEmptyLevel()
+	level.Tiles[4*level.Columns+4] = TILE_STEEL
+	tile := level.TileRect(4, 4)
+	if level.BlocksTank(Rect{X: tile.X - 30, Y: tile.Y, W: 30, H: 25}) {
+		t.Error("a tank touching the wall is blocked")
+	}
+	// the bounds of the rotated hull overlap the tile, the hull does not
+	hull := NewOBB(Rect{X: tile.X - 29, Y: tile.Y - 29, W: 30, H: 30}, 45)
+	if !hull.Bounds().HasIntersection(tile) {
+		t.Fatal("the bounds of the rotated hull should overlap the tile")
+	}
+	if level.BlocksHull(hull) {
+		t.Error("a rotated hull, just missing the wall with it's corner, is blocked")
+	}
+}
+
+func TestDamageTile(t *testing.T) {
+	tests := []struct {
+		name      string
+		tile      Tile
+		power     int
+		destroyed []bool // by every hit, in order
+	}{
+		{"brick, bullets", TILE_BRICK, BULLET_POWER, []bool{false, true}},
+		{"brick, a heavy bullet", TILE_BRICK, HEAVY_BULLET_POWER, []bool{true}},
+		{"steel, bullets", TILE_STEEL, BULLET_POWER, []bool{false, false, false, false}},
+		{"steel, heavy bullets", TILE_STEEL, HEAVY_BULLET_POWER, []bool{false, true}},
+		{"water", TILE_WATER, HEAVY_BULLET_POWER, []bool{false, false}},
+		{"trees", TILE_TREES, HEAVY_BULLET_POWER, []bool{false}},
+		{"empty", TILE_EMPTY, HEAVY_BULLET_POWER, []bool{false}},
+	}
+	for _, test := range tests {
+		level := mustParseLevel(t, levelText())
+		level.Tiles[0] = test.tile
+		level.Health[0] = test.tile.MaxHealth()
+		for hit, destroyed := range test.destroyed {
+			if level.DamageTile(0, 0, test.power) != destroyed {
+				t.Errorf("%s: hit %d destroyed the tile: %v, expected %v", test.name, hit+1, !destroyed, destroyed)
+			}
+			if !destroyed && (test.tile.MaxHealth() > 0) && (level.TileAt(0, 0) != test.tile) {
+				t.Errorf("%s: the tile is gone after hit %d", test.name, hit+1)
+			}
+		}
+		if test.destroyed[len(test.destroyed)-1] {
+			if (level.TileAt(0, 0) != TILE_EMPTY) || (level.Health[0] != 0) {
+				t.Errorf("%s: a destroyed tile is %d(health %d), expected an empty tile", test.name, level.TileAt(0, 0), level.Health[0])
+			}
+		} else if level.TileAt(0, 0) != test.tile {
+			t.Errorf("%s: tile %d, expected %d", test.name, level.TileAt(0, 0), test.tile)
+		}
+	}
+
+	// a damaged wall is saved as a damaged wall
+	level := mustParseLevel(t, levelText())
+	level.Tiles[0], level.Health[0] = TILE_BRICK, BRICK_HEALTH
+	level.Tiles[1], level.Health[1] = TILE_STEEL, STEEL_HEALTH
+	level.DamageTile(0, 0, BULLET_POWER)
+	level.DamageTile(1, 0, BULLET_POWER)
+	if !level.Damaged(0, 0) || level.Damaged(1, 0) {
+		t.Errorf("damaged: brick %v, steel %v, expected only the brick to be damaged", level.Damaged(0, 0), level.Damaged(1, 0))
+	}
+	level.DamageTile(1, 0, HEAVY_BULLET_POWER)
+	if row := strings.SplitN(level.String(), "\n", 2)[0]; !strings.HasPrefix(row, "bs.") {
+		t.Errorf("the first row is %q, expected a damaged brick and steel wall(\"bs.\")", row)
+	}
+
+	// outside of the level, nothing happens
+	if level.DamageTile(-1, 0, HEAVY_BULLET_POWER) || level.DamageTile(LEVEL_COLUMNS, 0, HEAVY_BULLET_POWER) {
+		t.Error("a tile outside of the level has been destroyed")
+	}
+}
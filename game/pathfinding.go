@@ -0,0 +1,144 @@
+// pathfinding.go
+package game
+
+import (
+	"container/heap"
+	"math"
+)
+
+/*
+
+A* pathfinding over the tiles of the level.
+A tank is bigger than a tile, so a tile is walkable for a tank, only if the whole tank fits there,
+when it's centre is at the centre of that tile.
+
+*/
+
+type Cell struct {
+	Column int
+	Row    int
+}
+
+// a tank of this size, with it's centre at the centre of the cell
+func (level *Level) tankRectAt(cell Cell, tankSize Size) Rect {
+	centre := level.TileRect(cell.Column, cell.Row).Centre()
+	return Rect{
+		X: centre.X - (tankSize.W / 2.0),
+		Y: centre.Y - (tankSize.H / 2.0),
+		W: tankSize.W,
+		H: tankSize.H,
+	}
+}
+
+func (world *World) walkable(cell Cell, tankSize Size) bool {
+	if (cell.Column < 0) || (cell.Row < 0) || (cell.Column >= world.Level.Columns) || (cell.Row >= world.Level.Rows) {
+		return false
+	}
+	rect := world.Level.tankRectAt(cell, tankSize)
+	return world.IsInsideArena(rect) && !world.Level.BlocksTank(rect)
+}
+
+func (world *World) CellOf(point Point) Cell {
+	column, row := world.Level.Cell(point)
+	return Cell{column, row}
+}
+
+func (world *World) CellCentre(cell Cell) Point {
+	return world.Level.TileRect(cell.Column, cell.Row).Centre()
+}
+
+type pathNode struct {
+	cell     Cell
+	priority float32 // cost so far + heuristic
+	index    int     // index in the heap
+}
+
+type pathQueue []*pathNode
+
+func (queue pathQueue) Len() int           { return len(queue) }
+func (queue pathQueue) Less(i, j int) bool { return queue[i].priority < queue[j].priority }
+func (queue pathQueue) Swap(i, j int) {
+	queue[i], queue[j] = queue[j], queue[i]
+	queue[i].index = i
+	queue[j].index = j
+}
+func (queue *pathQueue) Push(x interface{}) {
+	node := x.(*pathNode)
+	node.index = len(*queue)
+	*queue = append(*queue, node)
+}
+func (queue *pathQueue) Pop() interface{} {
+	old := *queue
+	node := old[len(old)-1]
+	*queue = old[:len(old)-1]
+	return node
+}
+
+var PATH_DIRECTIONS [8]Cell = [8]Cell{
+	{1, 0}, {-1, 0}, {0, 1}, {0, -1}, // straight
+	{1, 1}, {1, -1}, {-1, 1}, {-1, -1}, // diagonal
+}
+
+/*Returns the cells from start(not included) to goal(included), or nil if there is no path.
+If the goal itself is not walkable(for example, the player is next to a wall), the path
+goes to the nearest walkable cell, which has been reached.*/
+func (world *World) FindPath(start Cell, goal Cell, tankSize Size) []Cell {
+	costs := map[Cell]float32{start: 0.0}
+	cameFrom := map[Cell]Cell{}
+	heuristic := func(cell Cell) float32 {
+		return float32(math.Hypot(float64(cell.Column-goal.Column), float64(cell.Row-goal.Row)))
+	}
+	queue := &pathQueue{{cell: start, priority: heuristic(start)}}
+	best := start // nearest cell to the goal, reached so far
+
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(*pathNode).cell
+		if current == goal {
+			best = goal
+			break
+		}
+		if heuristic(current) < heuristic(best) {
+			best = current
+		}
+		for _, direction := range PATH_DIRECTIONS {
+			next := Cell{current.Column + direction.Column, current.Row + direction.Row}
+			if !world.walkable(next, tankSize) {
+				continue
+			}
+			if (direction.Column != 0) && (direction.Row != 0) && // no cutting corners
+				(!world.walkable(Cell{current.Column + direction.Column, current.Row}, tankSize) ||
+					!world.walkable(Cell{current.Column, current.Row + direction.Row}, tankSize)) {
+				continue
+			}
+			cost := costs[current] + float32(math.Hypot(float64(direction.Column), float64(direction.Row)))
+			if oldCost, ok := costs[next]; ok && (oldCost <= cost) {
+				continue
+			}
+			costs[next] = cost
+			cameFrom[next] = current
+			heap.Push(queue, &pathNode{cell: next, priority: cost + heuristic(next)})
+		}
+	}
+
+	if best == start {
+		return nil
+	}
+	var path []Cell
+	for cell := best; cell != start; cell = cameFrom[cell] {
+		path = append([]Cell{cell}, path...)
+	}
+	return path
+}
+
+// true, if there is no wall(brick or steel) between the two points, which would stop a bullet
+func (world *World) LineOfSight(from Point, to Point) bool {
+	distance := float32(math.Hypot(float64(to.X-from.X), float64(to.Y-from.Y)))
+	step := world.Level.TileSize / 4.0
+	for travelled := float32(0.0); travelled < distance; travelled += step {
+		t := travelled / distance
+		if world.Level.BlocksBullet(Point{from.X + (to.X-from.X)*t, from.Y + (to.Y-from.Y)*t}) {
+			return false
+		}
+	}
+	return true
+}
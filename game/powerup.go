@@ -0,0 +1,173 @@
+// powerup.go
+package game
+
+import (
+	"fmt"
+)
+
+/*
+
+Destroyed enemy tanks sometimes drop a power-up(see LevelSettings.PowerUpChance), which of them is picked by the
+weights of the level(LevelSettings.PowerUps). A power-up lies where the enemy tank was, until a player tank drives
+over it, or until it is gone after POWER_UP_LIFE_SPAN seconds(it blinks before that, see PowerUp.Visible).
+
+Some power-ups work at once(an extra life, the bomb), the others for a while: the shield and rapid fire on the
+tank of the player who picked them(see Tank.Shield and Tank.RapidFire), freezing the enemy tanks and fortifying
+the walls on the whole world(see World.Frozen and World.Fortified).
+There is no base in this game, so the fortification turns every brick wall of the level into steel, until it ends.
+
+*/
+
+type PowerUpKind int
+
+const (
+	POWER_UP_SHIELD     PowerUpKind = iota // the tank can not be damaged
+	POWER_UP_LIFE                          // one more life
+	POWER_UP_RAPID_FIRE                    // the weapon cools down and reloads faster
+	POWER_UP_FREEZE                        // the enemy tanks stop
+	POWER_UP_BOMB                          // destroys every enemy tank
+	POWER_UP_FORTIFY                       // the brick walls turn into steel
+)
+
+var POWER_UP_NAMES map[PowerUpKind]string = map[PowerUpKind]string{
+	POWER_UP_SHIELD:     "shield",
+	POWER_UP_LIFE:       "life",
+	POWER_UP_RAPID_FIRE: "rapidFire",
+	POWER_UP_FREEZE:     "freeze",
+	POWER_UP_BOMB:       "bomb",
+	POWER_UP_FORTIFY:    "fortify",
+}
+
+func FindPowerUp(name string) (PowerUpKind, error) {
+	for kind, powerUpName := range POWER_UP_NAMES {
+		if powerUpName == name {
+			return kind, nil
+		}
+	}
+	return POWER_UP_SHIELD, fmt.Errorf("unknown power-up %q", name)
+}
+
+const (
+	POWER_UP_SIZE           float32 = 30   // pixels
+	POWER_UP_LIFE_SPAN      float32 = 10.0 // seconds, until a power-up is gone
+	POWER_UP_BLINK_TIME     float32 = 3.0  // seconds, a power-up blinks before it is gone
+	POWER_UP_BLINK_INTERVAL float32 = 0.15 // seconds
+
+	SHIELD_TIME       float32 = 8.0  // seconds
+	RAPID_FIRE_TIME   float32 = 10.0 // seconds
+	RAPID_FIRE_FACTOR float32 = 2.0  // the cooldown and the reload of the weapon run this many times faster
+	FREEZE_TIME       float32 = 6.0  // seconds
+	FORTIFY_TIME      float32 = 15.0 // seconds
+)
+
+type PowerUp struct {
+	Kind        PowerUpKind
+	BoundingBox Rect
+	Age         float32 // simulated seconds, since it was dropped(for the animation)
+}
+
+// the power-up blinks, before it is gone
+func (powerUp PowerUp) Visible() bool {
+	left := POWER_UP_LIFE_SPAN - powerUp.Age
+	if left > POWER_UP_BLINK_TIME {
+		return true
+	}
+	return int(left/POWER_UP_BLINK_INTERVAL)%2 == 0
+}
+
+// the enemy tank has been destroyed, it may drop a power-up, where it was
+func (world *World) dropPowerUp(tankBoundingBox Rect) {
+	settings := world.config.LevelSettings
+	if (settings.PowerUpChance <= 0.0) || (world.r.Float32() >= settings.PowerUpChance) {
+		return // levels without power-ups do not use the random numbers, so they play as before
+	}
+	var kind PowerUpKind
+	if name, weighted := world.pickByWeight(settings.PowerUps); weighted {
+		kind, _ = FindPowerUp(name) // the level settings have been validated
+	} else {
+		kind = PowerUpKind(world.r.Intn(len(POWER_UP_NAMES))) // every power-up equally
+	}
+	centre := tankBoundingBox.Centre()
+	box := Rect{X: centre.X - (POWER_UP_SIZE / 2.0), Y: centre.Y - (POWER_UP_SIZE / 2.0), W: POWER_UP_SIZE, H: POWER_UP_SIZE}
+	world.PowerUps = append(world.PowerUps, PowerUp{Kind: kind, BoundingBox: box})
+}
+
+// ages the power-ups(removing the old ones), lets the player tanks pick them up, and ends the effects on the world
+func (world *World) updatePowerUps(dt float32) {
+	for i := 0; i < len(world.PowerUps); i++ {
+		world.PowerUps[i].Age += dt
+		picker := -1
+		for index := range world.Players {
+			if !world.Players[index].Lost && world.Players[index].Tank.Hull().Intersects(NewOBB(world.PowerUps[i].BoundingBox, 0.0)) {
+				picker = index
+				break
+			}
+		}
+		if picker != -1 {
+			world.pickUp(picker, world.PowerUps[i].Kind)
+		}
+		if (picker != -1) || (world.PowerUps[i].Age >= POWER_UP_LIFE_SPAN) {
+			world.PowerUps = append(world.PowerUps[:i], world.PowerUps[i+1:]...) // in order, the older ones are drawn first
+			i--
+		}
+	}
+
+	if world.Frozen > 0.0 {
+		world.Frozen -= dt
+	}
+	if world.Fortified > 0.0 {
+		world.Fortified -= dt
+		if world.Fortified <= 0.0 {
+			world.unfortify()
+		}
+	}
+}
+
+func (world *World) pickUp(player int, kind PowerUpKind) {
+	tank := &world.Players[player].Tank
+	switch kind {
+	case POWER_UP_SHIELD:
+		tank.Shield = SHIELD_TIME
+	case POWER_UP_LIFE:
+		tank.Lives += 1
+	case POWER_UP_RAPID_FIRE:
+		tank.RapidFire = RAPID_FIRE_TIME
+	case POWER_UP_FREEZE:
+		world.Frozen = FREEZE_TIME
+	case POWER_UP_BOMB:
+		for len(world.EnemyTanks) > 0 {
+			world.scoreKill(player, world.enemyScore(&world.EnemyTanks[len(world.EnemyTanks)-1]))
+			world.destroyEnemyTank(len(world.EnemyTanks) - 1)
+		}
+	case POWER_UP_FORTIFY:
+		if world.Fortified <= 0.0 {
+			world.fortify()
+		}
+		world.Fortified = FORTIFY_TIME
+	}
+}
+
+// every brick wall turns into a steel wall(with full health), until unfortify
+func (world *World) fortify() {
+	level := world.Level
+	world.fortifiedTiles = world.fortifiedTiles[:0]
+	for index, tile := range level.Tiles {
+		if tile == TILE_BRICK {
+			level.Tiles[index] = TILE_STEEL
+			level.Health[index] = TILE_STEEL.MaxHealth()
+			world.fortifiedTiles = append(world.fortifiedTiles, index)
+		}
+	}
+}
+
+// the fortified walls, which are still standing, are brick walls again(with full health)
+func (world *World) unfortify() {
+	level := world.Level
+	for _, index := range world.fortifiedTiles {
+		if level.Tiles[index] == TILE_STEEL {
+			level.Tiles[index] = TILE_BRICK
+			level.Health[index] = TILE_BRICK.MaxHealth()
+		}
+	}
+	world.fortifiedTiles = world.fortifiedTiles[:0]
+}
@@ -0,0 +1,161 @@
+package game
+
+import (
+	"fmt"
+	"testing"
+)
+
+// a power-up under the player tank, it is picked up on the next step
+func dropUnderPlayer(world *World, index int, kind PowerUpKind) {
+	centre := world.Players[index].Tank.BoundingBox.Centre()
+	box := Rect{X: centre.X - (POWER_UP_SIZE / 2.0), Y: centre.Y - (POWER_UP_SIZE / 2.0), W: POWER_UP_SIZE, H: POWER_UP_SIZE}
+	world.PowerUps = append(world.PowerUps, PowerUp{Kind: kind, BoundingBox: box})
+}
+
+func stepFor(world *World, seconds float32) {
+	for step := 0; float32(step)*TEST_TIMESTEP < seconds; step++ {
+		world.Step(TEST_TIMESTEP)
+	}
+}
+
+func TestShieldRunsOut(t *testing.T) {
+	world := quietWorld([]PlayerConfig{{}})
+	placeEnemyTank(world, Point{10, 10}, 1) // so that the level is not won
+	tank := &world.Players[0].Tank
+	dropUnderPlayer(world, 0, POWER_UP_SHIELD)
+	world.Step(TEST_TIMESTEP)
+	if (len(world.PowerUps) != 0) || (tank.Shield <= 0.0) {
+		t.Fatalf("the shield has not been picked up(%d power-ups left, shield %f)", len(world.PowerUps), tank.Shield)
+	}
+
+	maxHealth := world.config.Rules.PlayerTankMaxHealth
+	shootAtPlayer(world, 0)
+	stepUntilBulletsAreGone(world)
+	if tank.Health != maxHealth {
+		t.Errorf("health %d with the shield, expected no damage", tank.Health)
+	}
+	stepFor(world, SHIELD_TIME-1.0)
+	if tank.Shield <= 0.0 {
+		t.Fatal("the shield ran out early")
+	}
+	stepFor(world, 1.0)
+	if tank.Shield > 0.0 {
+		t.Fatalf("the shield is still on(%f seconds left), after %f seconds", tank.Shield, SHIELD_TIME)
+	}
+	shootAtPlayer(world, 0)
+	stepUntilBulletsAreGone(world)
+	if tank.Health != maxHealth-1 {
+		t.Errorf("health %d after the shield ran out, expected %d", tank.Health, maxHealth-1)
+	}
+}
+
+func TestRapidFireRunsOut(t *testing.T) {
+	world := quietWorld([]PlayerConfig{{}})
+	placeEnemyTank(world, Point{10, 10}, 1)
+	tank := &world.Players[0].Tank
+	dropUnderPlayer(world, 0, POWER_UP_RAPID_FIRE)
+	world.Step(TEST_TIMESTEP)
+	if tank.RapidFire <= 0.0 {
+		t.Fatal("rapid fire has not been picked up")
+	}
+
+	// the cooldown runs RAPID_FIRE_FACTOR times faster
+	tank.Arm(weaponOf("ricochet"))
+	tank.Shoot(world.config.BulletSize, world.config.Rules.BulletVelocity, world.r)
+	tank.UpdateWeapon(tank.Weapon.Cooldown / RAPID_FIRE_FACTOR)
+	if !tank.CanShoot() {
+		t.Error("the weapon has not cooled down, RAPID_FIRE_FACTOR times faster")
+	}
+
+	stepFor(world, RAPID_FIRE_TIME-1.0)
+	if tank.RapidFire <= 0.0 {
+		t.Fatal("rapid fire ran out early")
+	}
+	stepFor(world, 1.0)
+	if tank.RapidFire > 0.0 {
+		t.Fatalf("rapid fire is still on(%f seconds left), after %f seconds", tank.RapidFire, RAPID_FIRE_TIME)
+	}
+	tank.Shoot(world.config.BulletSize, world.config.Rules.BulletVelocity, world.r)
+	tank.UpdateWeapon(tank.Weapon.Cooldown / RAPID_FIRE_FACTOR)
+	if tank.CanShoot() {
+		t.Error("the weapon still cools down faster, after rapid fire")
+	}
+}
+
+func TestFortify(t *testing.T) {
+	world := levelWorld(t, "BbSB................", "....................", "..................BB")
+	placeEnemyTank(world, Point{200, 10}, 1)
+	level := world.Level
+	dropUnderPlayer(world, 0, POWER_UP_FORTIFY)
+	world.Step(TEST_TIMESTEP)
+	if world.Fortified <= 0.0 {
+		t.Fatal("fortify has not been picked up")
+	}
+	for _, column := range []int{0, 1, 2, 3} {
+		if (level.TileAt(column, 0) != TILE_STEEL) || (level.Health[column] != STEEL_HEALTH) {
+			t.Errorf("column %d: tile %d(health %d) after fortifying, expected steel with full health", column, level.TileAt(column, 0), level.Health[column])
+		}
+	}
+	// a fortified wall is destroyed by heavy bullets, it does not come back
+	level.DamageTile(3, 0, HEAVY_BULLET_POWER)
+	level.DamageTile(3, 0, HEAVY_BULLET_POWER)
+
+	stepFor(world, FORTIFY_TIME)
+	if world.Fortified > 0.0 {
+		t.Fatalf("still fortified(%f seconds left), after %f seconds", world.Fortified, FORTIFY_TIME)
+	}
+	tests := []struct {
+		column int
+		row    int
+		tile   Tile
+		health int
+	}{
+		{0, 0, TILE_BRICK, BRICK_HEALTH},
+		{1, 0, TILE_BRICK, BRICK_HEALTH}, // the damaged brick wall comes back with full health
+		{2, 0, TILE_STEEL, STEEL_HEALTH}, // steel was steel before
+		{3, 0, TILE_EMPTY, 0},
+		{18, 2, TILE_BRICK, BRICK_HEALTH},
+	}
+	for _, test := range tests {
+		index := test.row*level.Columns + test.column
+		if (level.TileAt(test.column, test.row) != test.tile) || (level.Health[index] != test.health) {
+			t.Errorf("(%d, %d): tile %d(health %d) after the fortification, expected %d(health %d)", test.column, test.row, level.TileAt(test.column, test.row), level.Health[index], test.tile, test.health)
+		}
+	}
+}
+
+// the drops come from the random numbers of the world, the same seed drops the same power-ups
+func TestPowerUpDrops(t *testing.T) {
+	drops := func(seed int64, chance float32) string {
+		config := DefaultConfig()
+		config.LevelSettings.PowerUpChance = chance
+		world := NewWorld(config, seed)
+		for kill := 0; kill < 100; kill++ {
+			world.dropPowerUp(Rect{X: float32(kill), Y: 100, W: 60, H: 60})
+		}
+		result := fmt.Sprint(len(world.PowerUps))
+		for _, powerUp := range world.PowerUps {
+			result += fmt.Sprint(" ", powerUp.Kind, powerUp.BoundingBox.X)
+		}
+		return result + fmt.Sprint(" next ", world.r.Int63())
+	}
+	first := drops(1, 0.5)
+	if drops(1, 0.5) != first {
+		t.Error("the same seed dropped other power-ups")
+	}
+	if drops(2, 0.5) == first {
+		t.Error("another seed dropped the same power-ups")
+	}
+	var count int
+	fmt.Sscan(first, &count)
+	if (count < 25) || (count > 75) {
+		t.Errorf("%d power-ups dropped by 100 enemy tanks, at a chance of 0.5", count)
+	}
+
+	// without power-ups, no random numbers are drawn(the levels play as before)
+	config := DefaultConfig()
+	before := NewWorld(config, 1).r.Int63()
+	if none := drops(1, 0.0); none != fmt.Sprint("0 next ", before) {
+		t.Errorf("drops without power-ups: %s, expected none, and no random numbers used", none)
+	}
+}
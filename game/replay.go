@@ -0,0 +1,376 @@
+// replay.go
+package game
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+/*
+
+A replay file is everything needed to play a game again, exactly as it was played:
+the seed, the timestep, the first level of the campaign, the difficulty, the players, the rules, and the input of every step
+(the world is deterministic, see world.go).
+
+Format(all numbers are little endian):
+	"TNKR"                     magic
+	uint16                     version
+	int64                      seed
+	float32                    timestep(seconds)
+	uint16                     index of the first level of the campaign(since version 2)
+	uint8                      index of the difficulty in DIFFICULTIES(since version 3)
+	uint8                      number of players(since version 4, 1 before)
+	uint8                      game mode(since version 4)
+	uint8                      1 with friendly fire, 0 without(since version 4)
+	uint16, bytes              length and JSON of the Rules(since version 6, DefaultRules() before)
+	[32]byte                   hash of the levels of the campaign, with their maps(since version 7, see Campaign.Hash)
+	(bytes..., uvarint)...     runs of inputs: the input of every player(see Input.Encode), and for how many steps they were held
+
+Players hold the same keys for many steps, so the runs keep the file small.
+A replay is at most MAX_REPLAY_DURATION long, the run counts of a longer one are taken as corrupt.
+
+*/
+
+const (
+	REPLAY_MAGIC   string = "TNKR"
+	REPLAY_VERSION uint16 = 7
+)
+
+const (
+	INPUT_ROTATE_ANTI_CLOCK_WISE byte = 1 << iota
+	INPUT_ROTATE_CLOCK_WISE
+	INPUT_MOVE_UP
+	INPUT_MOVE_LEFT
+	INPUT_MOVE_DOWN
+	INPUT_MOVE_RIGHT
+	INPUT_SHOOT
+	INPUT_ANALOG // since version 5, followed by MoveX, MoveY, Aim(0 or 1) and AimAngle
+)
+
+const (
+	MAX_REPLAY_DURATION float32 = 4 * 60 * 60 // seconds, a longer replay is taken as a corrupt one(see ReadReplay)
+	MIN_TIMESTEP        float32 = 1.0 / 500.0 // seconds, the steps of a replay with a shorter timestep would not fit in MAX_REPLAY_DURATION
+)
+
+const (
+	INPUT_AXIS_MAX  float32 = 127 // MoveX and MoveY are from -INPUT_AXIS_MAX to INPUT_AXIS_MAX
+	INPUT_AIM_STEPS float32 = 256 // AimAngle steps in a full circle
+)
+
+// quantizes an axis of a gamepad(-1 to 1) for Input.MoveX and Input.MoveY
+func QuantizeAxis(value float32) int8 {
+	return int8(math.Round(float64(clamp(value, -1.0, 1.0) * INPUT_AXIS_MAX)))
+}
+
+// quantizes an angle in degrees for Input.AimAngle
+func QuantizeAngle(angle float32) uint8 {
+	steps := int(math.Round(float64(angle/360.0*INPUT_AIM_STEPS))) % int(INPUT_AIM_STEPS)
+	if steps < 0 {
+		steps += int(INPUT_AIM_STEPS)
+	}
+	return uint8(steps)
+}
+
+func (input Input) analog() bool {
+	return (input.MoveX != 0) || (input.MoveY != 0) || input.Aim
+}
+
+// one byte for the keys, and four more for the analog input, if there is any
+func (input Input) Encode() []byte {
+	var result byte
+	bits := []struct {
+		set bool
+		bit byte
+	}{
+		{input.RotateAntiClockWise, INPUT_ROTATE_ANTI_CLOCK_WISE},
+		{input.RotateClockWise, INPUT_ROTATE_CLOCK_WISE},
+		{input.MoveUp, INPUT_MOVE_UP},
+		{input.MoveLeft, INPUT_MOVE_LEFT},
+		{input.MoveDown, INPUT_MOVE_DOWN},
+		{input.MoveRight, INPUT_MOVE_RIGHT},
+		{input.Shoot, INPUT_SHOOT},
+		{input.analog(), INPUT_ANALOG},
+	}
+	for _, b := range bits {
+		if b.set {
+			result |= b.bit
+		}
+	}
+	if !input.analog() {
+		return []byte{result}
+	}
+	aim := byte(0)
+	if input.Aim {
+		aim = 1
+	}
+	return []byte{result, byte(input.MoveX), byte(input.MoveY), aim, input.AimAngle}
+}
+
+// decodes the first byte of an encoded input, the analog input is read by ReadReplay
+func DecodeInput(encoded byte) Input {
+	return Input{
+		RotateAntiClockWise: encoded&INPUT_ROTATE_ANTI_CLOCK_WISE != 0,
+		RotateClockWise:     encoded&INPUT_ROTATE_CLOCK_WISE != 0,
+		MoveUp:              encoded&INPUT_MOVE_UP != 0,
+		MoveLeft:            encoded&INPUT_MOVE_LEFT != 0,
+		MoveDown:            encoded&INPUT_MOVE_DOWN != 0,
+		MoveRight:           encoded&INPUT_MOVE_RIGHT != 0,
+		Shoot:               encoded&INPUT_SHOOT != 0,
+	}
+}
+
+//==============RECORDING==============
+
+type ReplayRecorder struct {
+	w       *bufio.Writer
+	players int
+	current []byte // the inputs of the current run
+	count   uint64 // length of the current run
+}
+
+// Writes the header(everything in the replay, except the inputs) immediately, the inputs are written on Record and Close
+func NewReplayRecorder(w io.Writer, header Replay) (*ReplayRecorder, error) {
+	recorder := &ReplayRecorder{w: bufio.NewWriter(w), players: header.Players}
+	if _, err := recorder.w.WriteString(REPLAY_MAGIC); err != nil {
+		return nil, err
+	}
+	friendlyFire := uint8(0)
+	if header.FriendlyFire {
+		friendlyFire = 1
+	}
+	for _, value := range []interface{}{REPLAY_VERSION, header.Seed, header.Timestep, uint16(header.StartLevel), uint8(header.Difficulty),
+		uint8(header.Players), uint8(header.Mode), friendlyFire} {
+		if err := binary.Write(recorder.w, binary.LittleEndian, value); err != nil {
+			return nil, err
+		}
+	}
+	if header.Rules == (Rules{}) {
+		header.Rules = DefaultRules()
+	}
+	rules, err := json.Marshal(header.Rules)
+	if err != nil {
+		return nil, err
+	}
+	if err := binary.Write(recorder.w, binary.LittleEndian, uint16(len(rules))); err != nil {
+		return nil, err
+	}
+	if _, err := recorder.w.Write(rules); err != nil {
+		return nil, err
+	}
+	if _, err := recorder.w.Write(header.Levels[:]); err != nil {
+		return nil, err
+	}
+	return recorder, nil
+}
+
+// Records the inputs of every player, of one step
+func (recorder *ReplayRecorder) Record(inputs []Input) error {
+	if len(inputs) != recorder.players {
+		return fmt.Errorf("expected the inputs of %d players, got %d", recorder.players, len(inputs))
+	}
+	var encoded []byte
+	for _, input := range inputs {
+		encoded = append(encoded, input.Encode()...)
+	}
+	if (recorder.count > 0) && bytes.Equal(encoded, recorder.current) {
+		recorder.count += 1
+		return nil
+	}
+	if err := recorder.writeRun(); err != nil {
+		return err
+	}
+	recorder.current = encoded
+	recorder.count = 1
+	return nil
+}
+
+func (recorder *ReplayRecorder) writeRun() error {
+	if recorder.count == 0 {
+		return nil
+	}
+	if _, err := recorder.w.Write(recorder.current); err != nil {
+		return err
+	}
+	var buffer [binary.MaxVarintLen64]byte
+	_, err := recorder.w.Write(buffer[:binary.PutUvarint(buffer[:], recorder.count)])
+	return err
+}
+
+// Writes the last run, and flushes, it does not close the underlying writer
+func (recorder *ReplayRecorder) Close() error {
+	if err := recorder.writeRun(); err != nil {
+		return err
+	}
+	recorder.count = 0
+	return recorder.w.Flush()
+}
+
+//==============PLAYING==============
+
+type Replay struct {
+	Seed         int64
+	Timestep     float32
+	StartLevel   int // index of the first level of the campaign
+	Difficulty   int // index in DIFFICULTIES
+	Players      int // number of players
+	Mode         Mode
+	FriendlyFire bool
+	Rules        Rules             // zero means DefaultRules()
+	Levels       [sha256.Size]byte // hash of the levels of the campaign(see Campaign.Hash), zero if it is not known(before version 7)
+	Inputs       [][]Input         // inputs of every player, of every step
+}
+
+// ReplayController controls the tank of one player, by the inputs of a replay, one on every step
+type ReplayController struct {
+	Replay *Replay
+	Player int // index of the player
+	Step   int // index of the next input
+}
+
+// the input of the next step
+func (controller *ReplayController) Next() Input {
+	if controller.Finished() {
+		return Input{}
+	}
+	return controller.Replay.Inputs[controller.Step][controller.Player]
+}
+
+func (controller *ReplayController) Finished() bool {
+	return controller.Step >= len(controller.Replay.Inputs)
+}
+
+func (controller *ReplayController) Update(world *World, tank *Tank, dt float32) Intent {
+	input := controller.Next()
+	if !controller.Finished() {
+		controller.Step += 1
+	}
+	return world.intentOf(input)
+}
+
+func ReadReplay(r io.Reader) (*Replay, error) {
+	reader := bufio.NewReader(r)
+
+	magic := make([]byte, len(REPLAY_MAGIC))
+	if _, err := io.ReadFull(reader, magic); err != nil {
+		return nil, err
+	}
+	if string(magic) != REPLAY_MAGIC {
+		return nil, errors.New("not a replay file")
+	}
+	var version uint16
+	if err := binary.Read(reader, binary.LittleEndian, &version); err != nil {
+		return nil, err
+	}
+	if (version == 0) || (version > REPLAY_VERSION) {
+		return nil, fmt.Errorf("unsupported replay version %d(expected %d)", version, REPLAY_VERSION)
+	}
+
+	replay := &Replay{Difficulty: DEFAULT_DIFFICULTY, Players: 1, Mode: MODE_COOP, Rules: DefaultRules()}
+	if err := binary.Read(reader, binary.LittleEndian, &replay.Seed); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(reader, binary.LittleEndian, &replay.Timestep); err != nil {
+		return nil, err
+	}
+	if !(replay.Timestep >= MIN_TIMESTEP) || math.IsInf(float64(replay.Timestep), 0) { // NaN is not >= anything
+		return nil, fmt.Errorf("invalid timestep %f", replay.Timestep)
+	}
+	// a corrupt run count could be anything, the steps are counted against this
+	maxSteps := uint64(MAX_REPLAY_DURATION / replay.Timestep)
+	if version >= 2 { // version 1 had only one level
+		var startLevel uint16
+		if err := binary.Read(reader, binary.LittleEndian, &startLevel); err != nil {
+			return nil, err
+		}
+		replay.StartLevel = int(startLevel)
+	}
+	if version >= 3 { // before version 3, there were no difficulties
+		var difficulty uint8
+		if err := binary.Read(reader, binary.LittleEndian, &difficulty); err != nil {
+			return nil, err
+		}
+		if int(difficulty) >= len(DIFFICULTIES) {
+			return nil, fmt.Errorf("invalid difficulty %d", difficulty)
+		}
+		replay.Difficulty = int(difficulty)
+	}
+	if version >= 4 { // before version 4, there was only one player
+		var players, mode, friendlyFire uint8
+		for _, value := range []*uint8{&players, &mode, &friendlyFire} {
+			if err := binary.Read(reader, binary.LittleEndian, value); err != nil {
+				return nil, err
+			}
+		}
+		if players == 0 {
+			return nil, errors.New("a replay without players")
+		}
+		if _, ok := MODE_NAMES[Mode(mode)]; !ok {
+			return nil, fmt.Errorf("invalid game mode %d", mode)
+		}
+		replay.Players = int(players)
+		replay.Mode = Mode(mode)
+		replay.FriendlyFire = friendlyFire != 0
+	}
+	if version >= 6 { // before version 6, the rules could not be changed
+		var length uint16
+		if err := binary.Read(reader, binary.LittleEndian, &length); err != nil {
+			return nil, err
+		}
+		rules := make([]byte, length)
+		if _, err := io.ReadFull(reader, rules); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(rules, &replay.Rules); err != nil {
+			return nil, fmt.Errorf("invalid rules: %v", err)
+		}
+		if err := replay.Rules.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid rules: %v", err)
+		}
+	}
+	if version >= 7 { // before version 7, the levels were not checked
+		if _, err := io.ReadFull(reader, replay.Levels[:]); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		inputs := make([]Input, replay.Players)
+		for index := range inputs {
+			encoded, err := reader.ReadByte()
+			if (err == io.EOF) && (index == 0) {
+				return replay, nil
+			} else if err != nil {
+				return nil, fmt.Errorf("truncated replay: %v", err)
+			}
+			inputs[index] = DecodeInput(encoded)
+			if (encoded & INPUT_ANALOG) == 0 {
+				continue
+			}
+			var analog [4]byte
+			if _, err := io.ReadFull(reader, analog[:]); err != nil {
+				return nil, fmt.Errorf("truncated replay: %v", err)
+			}
+			inputs[index].MoveX = int8(analog[0])
+			inputs[index].MoveY = int8(analog[1])
+			inputs[index].Aim = analog[2] != 0
+			inputs[index].AimAngle = analog[3]
+		}
+		count, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return nil, fmt.Errorf("truncated replay: %v", err)
+		}
+		if count > maxSteps-uint64(len(replay.Inputs)) {
+			return nil, fmt.Errorf("corrupt replay: more than %d steps(%.0f seconds)", maxSteps, MAX_REPLAY_DURATION)
+		}
+		for i := uint64(0); i < count; i++ {
+			replay.Inputs = append(replay.Inputs, inputs)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package game
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// records the inputs with this header, and reads them back
+func roundTrip(t *testing.T, header Replay, inputs [][]Input) *Replay {
+	var file bytes.Buffer
+	recorder, err := NewReplayRecorder(&file, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range inputs {
+		if err := recorder.Record(step); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	replay, err := ReadReplay(&file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return replay
+}
+
+func TestReplayRoundTrip(t *testing.T) {
+	rules := DefaultRules()
+	rules.BulletVelocity = 321
+	rules.PlayerWeapon = "shotgun"
+	header := Replay{
+		Seed:         -1234567890123,
+		Timestep:     1.0 / 120.0,
+		StartLevel:   3,
+		Difficulty:   2,
+		Players:      2,
+		Mode:         MODE_VERSUS,
+		FriendlyFire: true,
+		Rules:        rules,
+		Levels:       [32]byte{1, 2, 3, 31: 4},
+	}
+	inputs := randomInputs(3000, header.Players, 1)
+	replay := roundTrip(t, header, inputs)
+
+	inputsRead := replay.Inputs
+	replay.Inputs, header.Inputs = nil, nil
+	if !reflect.DeepEqual(*replay, header) {
+		t.Errorf("header:\n%+v\nexpected:\n%+v", *replay, header)
+	}
+	if !reflect.DeepEqual(inputsRead, inputs) {
+		t.Errorf("%d steps of inputs read back differently(%d steps recorded)", len(inputsRead), len(inputs))
+	}
+
+	// no inputs at all
+	if replay := roundTrip(t, Replay{Seed: 1, Timestep: 1.0 / 60.0, Players: 1}, nil); len(replay.Inputs) != 0 {
+		t.Errorf("%d steps read from an empty replay", len(replay.Inputs))
+	}
+}
+
+// playing a replay gives the same game as playing the inputs it was recorded from
+func TestReplayPlayback(t *testing.T) {
+	inputs := randomInputs(1200, 2, 3)
+	replay := roundTrip(t, Replay{Seed: 5, Timestep: TEST_TIMESTEP, Players: 2}, inputs)
+
+	config := DefaultConfig()
+	for index := 0; index < replay.Players; index++ {
+		config.Players = append(config.Players, PlayerConfig{Controller: &ReplayController{Replay: replay, Player: index}})
+	}
+	world := NewWorld(config, replay.Seed)
+	live := playWorld(replay.Seed, inputs)
+	for step := range live {
+		world.Step(replay.Timestep)
+		if state := snapshot(world); state != live[step] {
+			t.Fatalf("the replay went apart from the game on step %d:\n%s\n%s", step, state, live[step])
+		}
+	}
+}
+
+func TestCorruptReplays(t *testing.T) {
+	var header bytes.Buffer
+	recorder, _ := NewReplayRecorder(&header, Replay{Seed: 1, Timestep: 1.0 / 60.0, Players: 1})
+	recorder.Close()
+	var count [binary.MaxVarintLen64]byte
+	// the same header, with another timestep
+	withTimestep := func(timestep float32) []byte {
+		var old, new [4]byte
+		binary.LittleEndian.PutUint32(old[:], math.Float32bits(1.0/60.0))
+		binary.LittleEndian.PutUint32(new[:], math.Float32bits(timestep))
+		return bytes.Replace(header.Bytes(), old[:], new[:], 1)
+	}
+	tests := []struct {
+		name  string
+		file  []byte
+		error string
+	}{
+		{"not a replay", []byte("PNG..."), "not a replay"},
+		{"truncated input", append(append([]byte{}, header.Bytes()...), INPUT_ANALOG, 1), "truncated"},
+		{"huge run", append(append(append([]byte{}, header.Bytes()...), 0), count[:binary.PutUvarint(count[:], 1<<40)]...), "corrupt"},
+		{"NaN timestep", withTimestep(float32(math.NaN())), "invalid timestep"},
+		{"infinite timestep", withTimestep(float32(math.Inf(1))), "invalid timestep"},
+		{"huge run at a NaN timestep", append(append(withTimestep(float32(math.NaN())), 0), count[:binary.PutUvarint(count[:], 50000000)]...), "invalid timestep"},
+	}
+	for _, test := range tests {
+		_, err := ReadReplay(bytes.NewReader(test.file))
+		if (err == nil) || !strings.Contains(err.Error(), test.error) {
+			t.Errorf("%s: error %v, expected %q", test.name, err, test.error)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// score.go
+package game
+
+import (
+	"math"
+)
+
+/*
+
+Every kill scores the points of the enemy tank(see EnemyClass.Score), times the kill streak of the player:
+a kill within Rules.StreakTime seconds of the last one makes the streak one longer(the multiplier is at most
+Rules.MaxStreak), the streak ends when the time runs out, or when the player tank loses a life.
+
+When a level is won, every player gets two bonuses(see Player.Stats):
+the time bonus, Rules.TimeBonus points for every second under the par time(Rules.ParTimePerEnemy seconds
+for every enemy tank of the level), and the accuracy bonus, Rules.AccuracyBonus times the part of the
+shots of the player, that hit an enemy(hitting the other player in co-op does not count).
+
+*/
+
+// what a player has done in one level(or in the whole game, added up by Add)
+type Stats struct {
+	Kills         int
+	Shots         int // projectiles shot(a shotgun shoots many in one shot)
+	Hits          int // projectiles, which hit an enemy tank(or in versus, another player tank)
+	BestStreak    int
+	TimeBonus     int
+	AccuracyBonus int
+}
+
+// the part of the shots, which hit a tank, from 0 to 1
+func (stats Stats) Accuracy() float32 {
+	if stats.Shots == 0 {
+		return 0.0
+	}
+	return float32(stats.Hits) / float32(stats.Shots)
+}
+
+// the stats of the levels together
+func (stats Stats) Add(other Stats) Stats {
+	stats.Kills += other.Kills
+	stats.Shots += other.Shots
+	stats.Hits += other.Hits
+	if other.BestStreak > stats.BestStreak {
+		stats.BestStreak = other.BestStreak
+	}
+	stats.TimeBonus += other.TimeBonus
+	stats.AccuracyBonus += other.AccuracyBonus
+	return stats
+}
+
+// the points of a kill are multiplied by this, while the kill streak of the player lasts(shown on the HUD)
+func (world *World) Multiplier(index int) int {
+	streak := world.Players[index].Streak
+	if streak < 1 {
+		return 1
+	}
+	if streak > world.config.Rules.MaxStreak {
+		return world.config.Rules.MaxStreak
+	}
+	return streak
+}
+
+// the player has destroyed a tank, worth these points(times the multiplier)
+func (world *World) scoreKill(index int, points int) {
+	player := &world.Players[index]
+	player.Streak += 1 // it is 0, if the last kill streak has ended
+	player.streakTimer = world.config.Rules.StreakTime
+	player.Score += points * world.Multiplier(index)
+	player.Stats.Kills += 1
+	if player.Streak > player.Stats.BestStreak {
+		player.Stats.BestStreak = player.Streak
+	}
+}
+
+// the kill streaks end, when their time runs out
+func (world *World) updateStreaks(dt float32) {
+	for index := range world.Players {
+		player := &world.Players[index]
+		if player.streakTimer > 0.0 {
+			player.streakTimer -= dt
+			if player.streakTimer <= 0.0 {
+				player.Streak = 0
+			}
+		}
+	}
+}
+
+// the level has been won, every player still playing gets the time and the accuracy bonus
+func (world *World) awardBonuses() {
+	rules := world.config.Rules
+	par := rules.ParTimePerEnemy * float32(world.config.LevelSettings.MaxNumOfEnemyTanks)
+	for index := range world.Players {
+		player := &world.Players[index]
+		if player.Lost {
+			continue
+		}
+		if par > world.Time {
+			player.Stats.TimeBonus = int(math.Round(float64(float32(rules.TimeBonus) * (par - world.Time))))
+		}
+		player.Stats.AccuracyBonus = int(math.Round(float64(float32(rules.AccuracyBonus) * player.Stats.Accuracy())))
+		player.Score += player.Stats.TimeBonus + player.Stats.AccuracyBonus
+	}
+}
@@ -0,0 +1,52 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestKillStreaks(t *testing.T) {
+	config := DefaultConfig()
+	config.Mode = MODE_VERSUS // no enemy tanks, only the kills of the test
+	config.Players = []PlayerConfig{{}, {}}
+	config.Rules.StreakTime = 1.0
+	config.Rules.MaxStreak = 3
+	world := NewWorld(config, 1)
+	player := &world.Players[0]
+
+	// the points of every kill, times the multiplier of the streak(up to MaxStreak)
+	for kill, expected := range []int{100, 300, 600, 900} {
+		world.scoreKill(0, 100)
+		if player.Score != expected {
+			t.Fatalf("kill %d: score %d, expected %d", kill+1, player.Score, expected)
+		}
+		world.updateStreaks(0.5)
+	}
+	if (player.Stats.Kills != 4) || (player.Stats.BestStreak != 4) {
+		t.Errorf("stats %+v, expected 4 kills in a streak of 4", player.Stats)
+	}
+
+	// the streak runs out
+	world.updateStreaks(1.0)
+	if multiplier := world.Multiplier(0); multiplier != 1 {
+		t.Errorf("multiplier %d after the streak time, expected 1", multiplier)
+	}
+	world.scoreKill(0, 100)
+	if player.Score != 1000 {
+		t.Errorf("score %d after the streak ended, expected 1000", player.Score)
+	}
+
+	// losing a life ends the streak too
+	world.scoreKill(0, 100)
+	world.damagePlayerTank(0, config.Rules.PlayerTankMaxHealth)
+	if multiplier := world.Multiplier(0); multiplier != 1 {
+		t.Errorf("multiplier %d after losing a life, expected 1", multiplier)
+	}
+}
+
+func TestStreakTimeValidation(t *testing.T) {
+	rules := DefaultRules()
+	rules.StreakTime = 0.0
+	if rules.Validate() == nil {
+		t.Error("streakTime 0 is valid, the streaks would never end")
+	}
+}
@@ -0,0 +1,206 @@
+// settings.go
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+// the defaults of Rules
+///////////////////////////////////////////////////////////////////////////////////////////////////////////////
+const (
+	//==============GAMEPLAY==============
+	BULLET_VELOCITY               float32 = 500
+	TANK_ROTATION_ANGLE           float32 = 500 // degrees per second, TODO : Why so low rotation on setting this to 5?(maybe due to delta calculation)
+	PLAYER_TANK_VELOCITY          float32 = 300
+	EXPLOSION_ANIMATION_LIFE_SPAN float32 = 0.5 // seconds
+
+	//==============TANK CONTROLS==============
+	TANK_CONTROLS     bool    = false // the player tanks drive along their heading, instead of moving up, down, left and right
+	TANK_ACCELERATION float32 = 900   // pixels per second per second, full speed in a third of a second
+	TANK_FRICTION     float32 = 600   // pixels per second per second, slowing down without the throttle
+
+	//==============PLAYER HEALTH==============
+	PLAYER_TANK_MAX_HEALTH      int     = 3   // number of enemy bullets, the player tank can take before losing a life
+	PLAYER_TANK_LIVES           int     = 3   // number of lives, at the start of the game
+	PLAYER_TANK_INVULNERABILITY float32 = 2.0 // seconds, after respawning
+	PLAYER_TANK_BLINK_TIME      float32 = 0.1 // seconds, the player tank blinks while it is invulnerable
+	ENEMY_TANK_BULLET_DAMAGE    int     = 1
+	PLAYER_TANK_BULLET_DAMAGE   int     = 1 // see LevelSettings.EnemyTankHealth
+
+	//==============SCORE==============
+	ENEMY_TANK_SCORE  int = 100 // for destroying an enemy tank
+	PLAYER_TANK_SCORE int = 500 // for destroying a life of the other player, in versus
+
+	//==============BONUSES(see score.go)==============
+	STREAK_TIME        float32 = 3.0  // seconds, a kill within this time of the last one makes the kill streak longer
+	MAX_STREAK         int     = 4    // the points of a kill are multiplied by the kill streak, up to this
+	PAR_TIME_PER_ENEMY float32 = 6.0  // seconds, for every enemy tank of the level
+	TIME_BONUS         int     = 10   // points for every second under the par time
+	ACCURACY_BONUS     int     = 1000 // points, when every shot hits a tank
+
+	//==============SPECIAL FLAGS==============
+	CRAZY_TANKS bool = false // A special flag, toggle it, and enjoy!(every enemy tank will be a crazy tank, see LevelSettings.CrazyTankChance)
+)
+
+//==============LEVEL MAP==============
+const (
+	TILE_SIZE     float32 = 25 // pixels
+	LEVEL_COLUMNS int     = 20 // DEFAULT_ARENA_WIDTH / TILE_SIZE
+	LEVEL_ROWS    int     = 20 // DEFAULT_ARENA_HEIGHT / TILE_SIZE
+
+	//==============DESTRUCTIBLE WALLS==============
+	BRICK_HEALTH       int     = 2   // number of bullets, a brick tile can take
+	STEEL_HEALTH       int     = 2   // number of heavy bullets, a steel tile can take
+	BULLET_POWER       int     = 1   // damage, a bullet does to the walls
+	HEAVY_BULLET_POWER int     = 2   // only bullets with at least this power, can damage steel walls
+	WALL_EXPLOSION     float32 = 0.4 // scale of the explosion of a destroyed tile
+)
+
+//==============DEFAULT SIZES==============
+// These are the sizes of the textures in the resources directory,
+// they are used when the world runs without any textures(headless)
+const (
+	DEFAULT_ARENA_WIDTH      float32 = 500
+	DEFAULT_ARENA_HEIGHT     float32 = 500
+	DEFAULT_TANK_WIDTH       float32 = 60
+	DEFAULT_TANK_HEIGHT      float32 = 60
+	DEFAULT_BULLET_WIDTH     float32 = 30
+	DEFAULT_BULLET_HEIGHT    float32 = 8
+	DEFAULT_EXPLOSION_FRAMES int     = 64 // steps of an explosion, the renderer maps them onto the frames of it's sprite sheet
+)
+
+type Config struct {
+	ArenaWidth      float32
+	ArenaHeight     float32
+	PlayerTankSize  Size
+	EnemyTankSize   Size
+	BulletSize      Size
+	ExplosionFrames int
+	Level           *Level // the walls, water and trees of the arena, nil means an empty arena
+	LevelSettings   LevelSettings
+	Difficulty      Difficulty     // how good the enemy tanks are, one of DIFFICULTIES
+	Players         []PlayerConfig // nil means one player tank, standing still
+	Mode            Mode
+	FriendlyFire    bool  // in co-op, the bullets of the players hurt each other too(in versus, they always do)
+	Rules           Rules // zero means DefaultRules()
+}
+
+/*The gameplay settings, which can be changed without rebuilding the game(see config.go of the main package, help is shown with the flags).
+The same inputs give a different game with different rules, so the rules are saved in the replays too.*/
+type Rules struct {
+	BulletVelocity            float32 `json:"bulletVelocity" help:"pixels per second"`
+	TankRotationSpeed         float32 `json:"tankRotationSpeed" help:"degrees per second"`
+	PlayerTankVelocity        float32 `json:"playerTankVelocity" help:"pixels per second"`
+	ExplosionLifeSpan         float32 `json:"explosionLifeSpan" help:"seconds"`
+	TankControls              bool    `json:"tankControls" help:"the player tanks drive forward and backward along their heading and turn their hulls, and the turrets aim separately"`
+	TankAcceleration          float32 `json:"tankAcceleration" help:"pixels per second per second, with tankControls"`
+	TankFriction              float32 `json:"tankFriction" help:"pixels per second per second, with tankControls, the tank slows down without the throttle"`
+	PlayerTankMaxHealth       int     `json:"playerTankMaxHealth" help:"number of enemy bullets, the player tank can take before losing a life"`
+	PlayerTankLives           int     `json:"playerTankLives" help:"number of lives, at the start of the game"`
+	PlayerTankInvulnerability float32 `json:"playerTankInvulnerability" help:"seconds, after respawning"`
+	EnemyTankBulletDamage     int     `json:"enemyTankBulletDamage" help:"damage of the bullets of the enemy tanks"`
+	PlayerTankBulletDamage    int     `json:"playerTankBulletDamage" help:"damage of the bullets of the player tanks"`
+	EnemyTankScore            int     `json:"enemyTankScore" help:"for destroying an enemy tank"`
+	PlayerTankScore           int     `json:"playerTankScore" help:"for destroying a life of the other player, in versus"`
+	CrazyTanks                bool    `json:"crazyTanks" help:"every enemy tank will be a crazy tank"`
+	PlayerWeapon              string  `json:"playerWeapon" help:"weapon of the player tanks: cannon, machineGun, ricochet, missile, shotgun or artillery(see WEAPONS in weapon.go)"`
+	EnemyWeapon               string  `json:"enemyWeapon" help:"weapon of the enemy tanks"`
+	StreakTime                float32 `json:"streakTime" help:"seconds, a kill within this time of the last one makes the kill streak longer"`
+	MaxStreak                 int     `json:"maxStreak" help:"the points of a kill are multiplied by the kill streak, up to this(1 turns the multipliers off)"`
+	ParTimePerEnemy           float32 `json:"parTimePerEnemy" help:"seconds, for every enemy tank of the level, the time bonus is given for winning faster"`
+	TimeBonus                 int     `json:"timeBonus" help:"points for every second under the par time, when a level is won"`
+	AccuracyBonus             int     `json:"accuracyBonus" help:"points, when a level is won and every shot has hit a tank(less for less accuracy)"`
+}
+
+func DefaultRules() Rules {
+	return Rules{
+		BulletVelocity:            BULLET_VELOCITY,
+		TankRotationSpeed:         TANK_ROTATION_ANGLE,
+		PlayerTankVelocity:        PLAYER_TANK_VELOCITY,
+		ExplosionLifeSpan:         EXPLOSION_ANIMATION_LIFE_SPAN,
+		TankControls:              TANK_CONTROLS,
+		TankAcceleration:          TANK_ACCELERATION,
+		TankFriction:              TANK_FRICTION,
+		PlayerTankMaxHealth:       PLAYER_TANK_MAX_HEALTH,
+		PlayerTankLives:           PLAYER_TANK_LIVES,
+		PlayerTankInvulnerability: PLAYER_TANK_INVULNERABILITY,
+		EnemyTankBulletDamage:     ENEMY_TANK_BULLET_DAMAGE,
+		PlayerTankBulletDamage:    PLAYER_TANK_BULLET_DAMAGE,
+		EnemyTankScore:            ENEMY_TANK_SCORE,
+		PlayerTankScore:           PLAYER_TANK_SCORE,
+		CrazyTanks:                CRAZY_TANKS,
+		PlayerWeapon:              DEFAULT_WEAPON,
+		EnemyWeapon:               DEFAULT_WEAPON,
+		StreakTime:                STREAK_TIME,
+		MaxStreak:                 MAX_STREAK,
+		ParTimePerEnemy:           PAR_TIME_PER_ENEMY,
+		TimeBonus:                 TIME_BONUS,
+		AccuracyBonus:             ACCURACY_BONUS,
+	}
+}
+
+func (rules Rules) Validate() error {
+	if err := CheckFinite(rules); err != nil {
+		return err
+	}
+	switch {
+	case rules.BulletVelocity <= 0.0:
+		return errors.New("bulletVelocity must be more than 0")
+	case rules.TankRotationSpeed <= 0.0:
+		return errors.New("tankRotationSpeed must be more than 0")
+	case rules.PlayerTankVelocity <= 0.0:
+		return errors.New("playerTankVelocity must be more than 0")
+	case rules.ExplosionLifeSpan <= 0.0:
+		return errors.New("explosionLifeSpan must be more than 0")
+	case rules.TankAcceleration <= 0.0:
+		return errors.New("tankAcceleration must be more than 0")
+	case rules.TankFriction < 0.0:
+		return errors.New("tankFriction can not be negative")
+	case rules.PlayerTankMaxHealth < 1:
+		return errors.New("playerTankMaxHealth must be at least 1")
+	case rules.PlayerTankLives < 1:
+		return errors.New("playerTankLives must be at least 1")
+	case rules.PlayerTankInvulnerability < 0.0:
+		return errors.New("playerTankInvulnerability can not be negative")
+	case rules.EnemyTankBulletDamage < 0:
+		return errors.New("enemyTankBulletDamage can not be negative")
+	case rules.PlayerTankBulletDamage < 0:
+		return errors.New("playerTankBulletDamage can not be negative")
+	case rules.StreakTime <= 0.0:
+		return errors.New("streakTime must be more than 0(maxStreak 1 turns the multipliers off)")
+	case rules.MaxStreak < 1:
+		return errors.New("maxStreak must be at least 1")
+	case rules.ParTimePerEnemy < 0.0:
+		return errors.New("parTimePerEnemy can not be negative")
+	}
+	if (rules.EnemyTankScore < 0) || (rules.PlayerTankScore < 0) || (rules.TimeBonus < 0) || (rules.AccuracyBonus < 0) {
+		return fmt.Errorf("scores can not be negative(enemyTankScore %d, playerTankScore %d, timeBonus %d, accuracyBonus %d)", rules.EnemyTankScore, rules.PlayerTankScore, rules.TimeBonus, rules.AccuracyBonus)
+	}
+	for _, name := range []string{rules.PlayerWeapon, rules.EnemyWeapon} {
+		if _, err := FindWeapon(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type PlayerConfig struct {
+	Controller Controller // controls the player tank(see controller.go), nil means the player tank stands still
+	Lives      int        // lives of the player tank at the start of the level(the lives left, from the last level), 0 means Rules.PlayerTankLives
+	Score      int        // score from the last levels
+}
+
+func DefaultConfig() Config {
+	return Config{
+		ArenaWidth:      DEFAULT_ARENA_WIDTH,
+		ArenaHeight:     DEFAULT_ARENA_HEIGHT,
+		PlayerTankSize:  Size{DEFAULT_TANK_WIDTH, DEFAULT_TANK_HEIGHT},
+		EnemyTankSize:   Size{DEFAULT_TANK_WIDTH, DEFAULT_TANK_HEIGHT},
+		BulletSize:      Size{DEFAULT_BULLET_WIDTH, DEFAULT_BULLET_HEIGHT},
+		ExplosionFrames: DEFAULT_EXPLOSION_FRAMES,
+		LevelSettings:   DefaultLevelSettings(),
+		Difficulty:      DIFFICULTIES[DEFAULT_DIFFICULTY],
+		Rules:           DefaultRules(),
+	}
+}
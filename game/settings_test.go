@@ -0,0 +1,55 @@
+package game
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// NaN passes every range check, so the settings from the config file, the environment or the flags are checked for it first
+func TestNonFiniteSettings(t *testing.T) {
+	nan, inf := float32(math.NaN()), float32(math.Inf(1))
+	rules := []struct {
+		name   string
+		change func(*Rules)
+		error  string
+	}{
+		{"NaN bulletVelocity", func(rules *Rules) { rules.BulletVelocity = nan }, "bulletVelocity"},
+		{"infinite tankFriction", func(rules *Rules) { rules.TankFriction = inf }, "tankFriction"},
+		{"NaN streakTime", func(rules *Rules) { rules.StreakTime = nan }, "streakTime"},
+		{"negative infinite parTimePerEnemy", func(rules *Rules) { rules.ParTimePerEnemy = -inf }, "parTimePerEnemy"},
+	}
+	for _, test := range rules {
+		settings := DefaultRules()
+		test.change(&settings)
+		if err := settings.Validate(); (err == nil) || !strings.Contains(err.Error(), test.error) {
+			t.Errorf("%s: error %v, expected one about %s", test.name, err, test.error)
+		}
+	}
+
+	levels := []struct {
+		name   string
+		change func(*LevelSettings)
+		error  string
+	}{
+		{"NaN crazyTankChance", func(settings *LevelSettings) { settings.CrazyTankChance = nan }, "crazyTankChance"},
+		{"NaN enemyTankMaxNoUpdateTime", func(settings *LevelSettings) { settings.EnemyTankMaxNoUpdateTime = nan }, "enemyTankMaxNoUpdateTime"},
+		{"infinite enemySpawnOffTime", func(settings *LevelSettings) { settings.EnemySpawnOffTime = inf }, "enemySpawnOffTime"},
+		{"NaN weight of an enemy class", func(settings *LevelSettings) { settings.EnemyClasses = map[string]float32{"boss": nan} }, "boss"},
+		{"infinite weight of a power-up", func(settings *LevelSettings) { settings.PowerUps = map[string]float32{"shield": inf} }, "shield"},
+	}
+	for _, test := range levels {
+		settings := DefaultLevelSettings()
+		test.change(&settings)
+		if err := settings.Validate(); (err == nil) || !strings.Contains(err.Error(), test.error) {
+			t.Errorf("%s: error %v, expected one about %s", test.name, err, test.error)
+		}
+	}
+
+	if err := DefaultRules().Validate(); err != nil {
+		t.Errorf("the default rules are invalid: %v", err)
+	}
+	if err := DefaultLevelSettings().Validate(); err != nil {
+		t.Errorf("the default level settings are invalid: %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+// spatial.go
+package game
+
+/*
+
+The broadphase of the collision detection: a uniform grid over the arena, every cell has the ids of the
+objects overlapping it(the id of an object is usually it's index in it's slice). A query only looks at the
+cells under a rectangle, so finding the tanks or bullets near a tank does not depend on the number of
+objects in the whole arena.
+
+The walls do not need a grid of their own, the level already is one(see Level.BlocksHull).
+Objects outside the arena are kept in the cells at the edges, so nothing is lost.
+
+*/
+
+const SPATIAL_CELL_SIZE float32 = 64 // pixels, about the size of a tank
+
+type SpatialHash struct {
+	cellSize float32
+	columns  int
+	rows     int
+	cells    [][]int
+}
+
+func NewSpatialHash(width float32, height float32, cellSize float32) *SpatialHash {
+	hash := &SpatialHash{
+		cellSize: cellSize,
+		columns:  int(width/cellSize) + 1,
+		rows:     int(height/cellSize) + 1,
+	}
+	hash.cells = make([][]int, hash.columns*hash.rows)
+	return hash
+}
+
+// the cells under bounds(clamped to the grid)
+func (hash *SpatialHash) cellRange(bounds Rect) (int, int, int, int) {
+	clampCell := func(position float32, cells int) int {
+		cell := int(position / hash.cellSize)
+		if position < 0.0 {
+			cell = 0
+		}
+		if cell >= cells {
+			cell = cells - 1
+		}
+		return cell
+	}
+	return clampCell(bounds.X, hash.columns), clampCell(bounds.Y, hash.rows),
+		clampCell(bounds.X+bounds.W, hash.columns), clampCell(bounds.Y+bounds.H, hash.rows)
+}
+
+// removes every object(keeping the memory of the cells)
+func (hash *SpatialHash) Clear() {
+	for index := range hash.cells {
+		hash.cells[index] = hash.cells[index][:0]
+	}
+}
+
+func (hash *SpatialHash) Insert(id int, bounds Rect) {
+	firstColumn, firstRow, lastColumn, lastRow := hash.cellRange(bounds)
+	for row := firstRow; row <= lastRow; row++ {
+		for column := firstColumn; column <= lastColumn; column++ {
+			cell := &hash.cells[(row*hash.columns)+column]
+			*cell = append(*cell, id)
+		}
+	}
+}
+
+// bounds must be the same, as when the object was inserted
+func (hash *SpatialHash) Remove(id int, bounds Rect) {
+	firstColumn, firstRow, lastColumn, lastRow := hash.cellRange(bounds)
+	for row := firstRow; row <= lastRow; row++ {
+		for column := firstColumn; column <= lastColumn; column++ {
+			cell := &hash.cells[(row*hash.columns)+column]
+			for index := range *cell {
+				if (*cell)[index] == id {
+					(*cell)[index] = (*cell)[len(*cell)-1]
+					*cell = (*cell)[:len(*cell)-1]
+					break
+				}
+			}
+		}
+	}
+}
+
+func (hash *SpatialHash) Move(id int, from Rect, to Rect) {
+	if from == to {
+		return
+	}
+	hash.Remove(id, from)
+	hash.Insert(id, to)
+}
+
+// the id of the object has changed(it was moved in it's slice, like by RemoveElementFromBulletSlice)
+func (hash *SpatialHash) Relabel(from int, to int, bounds Rect) {
+	firstColumn, firstRow, lastColumn, lastRow := hash.cellRange(bounds)
+	for row := firstRow; row <= lastRow; row++ {
+		for column := firstColumn; column <= lastColumn; column++ {
+			cell := hash.cells[(row*hash.columns)+column]
+			for index := range cell {
+				if cell[index] == from {
+					cell[index] = to
+					break
+				}
+			}
+		}
+	}
+}
+
+/*Appends the ids of the objects in the cells under bounds to found, and returns it.
+These objects may overlap bounds, the caller checks them exactly. An object in more than one of the cells
+is found more than once.*/
+func (hash *SpatialHash) Query(bounds Rect, found []int) []int {
+	firstColumn, firstRow, lastColumn, lastRow := hash.cellRange(bounds)
+	for row := firstRow; row <= lastRow; row++ {
+		for column := firstColumn; column <= lastColumn; column++ {
+			found = append(found, hash.cells[(row*hash.columns)+column]...)
+		}
+	}
+	return found
+}
+
+//==============BULLETS==============
+
+// the bullets of one slice(like the bullets of a player), in a grid by the paths of their noses in the last step
+type bulletGrid struct {
+	hash    *SpatialHash
+	bullets *[]Bullet
+	found   []int // reused by the queries
+}
+
+func (grid *bulletGrid) reset(bullets *[]Bullet) {
+	grid.bullets = bullets
+	grid.hash.Clear()
+	for index := range *bullets {
+		grid.hash.Insert(index, (*bullets)[index].sweptBounds())
+	}
+}
+
+/*Removes the first bullet(the one with the smallest index), which hits the hull,
+returns it, and true if there was one. Same as RemoveBulletHitting, without looking at every bullet.*/
+func (grid *bulletGrid) removeHitting(hull OBB) (Bullet, bool) {
+	bullets := *grid.bullets
+	grid.found = grid.hash.Query(hull.Bounds(), grid.found[:0])
+	hit := -1
+	for _, index := range grid.found {
+		if ((hit == -1) || (index < hit)) && bullets[index].Hits(hull) {
+			hit = index
+		}
+	}
+	if hit == -1 {
+		return Bullet{}, false
+	}
+	last := len(bullets) - 1
+	grid.hash.Remove(hit, bullets[hit].sweptBounds())
+	if hit != last {
+		grid.hash.Relabel(last, hit, bullets[last].sweptBounds())
+	}
+	bullet := bullets[hit]
+	*grid.bullets = RemoveElementFromBulletSlice(bullets, hit)
+	return bullet, true
+}
@@ -0,0 +1,316 @@
+// tank.go
+package game
+
+import (
+	"math"
+)
+
+/*
+
+It is not easy, to create this file to follow only one function set.
+Let's say: tank.Move(moveX, moveY, delta) -> this seems to be taking the tank pointer, and change it's position.
+But in my case, it will take a value receiver, change it's position and return that. ((a tank) func Move(...) tank)
+this is because I need to check whether the tank is overlapping with other game objects or not.
+This checking(or rather collision detection) has been done in the world.go file, because that is the central place, where I
+have acces to all game objects, so I have to essentially return a new tank, and not mutate the receiver.
+
+In some cases the function signature makes it very clear that it will mutate, for those cases I have used pointer receivers
+like bullet.Update()
+
+func (controller *AIController) WillUpdate(delta float32) bool -> this is exceptional, it looks like it's not mutating but it needs to do that
+see it's usage in controller.go, there is no better or elegant way of doing it in any other way(maybe)
+
+
+*/
+
+type Bullet struct {
+	Velocity      float32
+	BoundingBox   Rect
+	RotationAngle float32
+	Power         int        // damage to the walls
+	Projectile    Projectile // what kind of projectile, for the renderer(see weapon.go)
+	Damage        int        // to the tanks, 0 means the damage of the rules(see DamageOr)
+	Range         float32    // pixels, the bullet is gone after flying this far, 0 means no limit
+	Bounces       int        // off the walls, left
+	Homing        float32    // degrees per second, the bullet turns towards the nearest target
+	lastNose      Point      // the nose before the last Update, the bullet hits whatever it has swept through since then
+	travelled     float32    // pixels
+}
+
+func (bullet *Bullet) Update(delta float32) {
+	bullet.lastNose = bullet.NosePosition()
+	bullet.BoundingBox.X += bullet.Velocity * delta * float32(math.Cos(DegreeToRadian(float64(bullet.RotationAngle))))
+	bullet.BoundingBox.Y += bullet.Velocity * delta * float32(math.Sin(DegreeToRadian(float64(bullet.RotationAngle))))
+	bullet.travelled += bullet.Velocity * delta
+}
+
+// the bullet hits, whatever it's nose is inside of(the bullet is drawn rotated around it's centre, so is it's nose)
+func (bullet Bullet) NosePosition() Point {
+	centre := bullet.BoundingBox.Centre()
+	radians := DegreeToRadian(float64(bullet.RotationAngle))
+	return Point{
+		centre.X + (bullet.BoundingBox.W / 2.0 * float32(math.Cos(radians))),
+		centre.Y + (bullet.BoundingBox.W / 2.0 * float32(math.Sin(radians))),
+	}
+}
+
+// the nose of the bullet has crossed the hull in the last step(see OBB.IntersectsSegment)
+func (bullet Bullet) Hits(hull OBB) bool {
+	return hull.IntersectsSegment(bullet.lastNose, bullet.NosePosition())
+}
+
+// around the path of the nose in the last step(for the broadphase, see spatial.go)
+func (bullet Bullet) sweptBounds() Rect {
+	nose := bullet.NosePosition()
+	return Rect{
+		X: float32(math.Min(float64(bullet.lastNose.X), float64(nose.X))),
+		Y: float32(math.Min(float64(bullet.lastNose.Y), float64(nose.Y))),
+		W: abs(nose.X - bullet.lastNose.X),
+		H: abs(nose.Y - bullet.lastNose.Y),
+	}
+}
+
+type Explosion struct {
+	Position     Point   // centre of the explosion
+	Frame        int     // the current step of the explosion(see Progress)
+	Scale        float32 // 1 for tanks, smaller for walls
+	Died         bool
+	frames       int
+	timer        float32 // simulated seconds, since the last frame change
+	noUpdateTime float32
+}
+
+func NewExplosion(tankBoundingBox Rect, frames int, lifeSpan float32) Explosion {
+	return Explosion{
+		Position:     tankBoundingBox.Centre(), // positioning exactly at the centre of the tank
+		Frame:        0,
+		Scale:        1.0,
+		Died:         false,
+		frames:       frames,
+		timer:        0.0,
+		noUpdateTime: lifeSpan / float32(frames),
+	}
+}
+
+// from 0(the start of the animation) to 1(the end), for the renderer
+func (explosion Explosion) Progress() float32 {
+	progress := (float32(explosion.Frame) + (explosion.timer / explosion.noUpdateTime)) / float32(explosion.frames)
+	if progress > 1.0 {
+		return 1.0
+	}
+	return progress
+}
+
+func (explosion *Explosion) Update(delta float32) {
+	explosion.timer += delta
+	if explosion.Frame == (explosion.frames - 1) {
+		explosion.Died = true
+	} else if explosion.timer >= explosion.noUpdateTime {
+		explosion.timer = 0.0
+		explosion.Frame += 1
+	}
+}
+
+/*One type for every tank, the player tank and the enemy tanks.
+What a tank does, is decided by it's Controller(see controller.go), so any tank can be
+controlled by the keyboard, a replay, the AI or a script.*/
+type Tank struct {
+	RotationAngle                float32
+	BoundingBox                  Rect
+	Velocity                     float32 // pixels per second, at full intent
+	RotationSpeed                float32 // degrees per second
+	Health                       int
+	MaxHealth                    int
+	Lives                        int
+	Controller                   Controller
+	Travelled                    float32 // pixels, for the animation of the treads
+	SinceShot                    float32 // simulated seconds since the last shot(for the muzzle flash), negative if the tank has not shot yet
+	Turret                       float32 // degrees, the turret from the heading of the hull(always 0, unless the tank is driven with tank controls)
+	Speed                        float32 // pixels per second along the heading, negative backwards(only with tank controls, see Accelerate)
+	rotationAnimationTargetAngle float32
+	Weapon                       Weapon      // see weapon.go
+	Ammo                         int         // shots left in the magazine(only if the weapon has a magazine)
+	Reloading                    float32     // seconds left, until the magazine is full again
+	Shield                       float32     // seconds left, the tank can not be damaged(a power-up, see powerup.go)
+	RapidFire                    float32     // seconds left, the weapon cools down and reloads faster(a power-up)
+	Class                        *EnemyClass // of an enemy tank(see enemy.go), nil for the player tanks
+	turretTargetAngle            float32     // the turret keeps aiming there, while the hull turns
+	cooldown                     float32     // seconds left, until the weapon can shoot again
+	invulnerableTimer            float32     // seconds left, until the tank can be damaged again
+	aimError                     float32     // degrees, see World.aimAtPlayer
+}
+
+func NewTank(size Size, velocity float32, rotationSpeed float32, initialRotationAngle float32, health int, lives int, controller Controller) Tank {
+	return Tank{
+		RotationAngle:                initialRotationAngle,
+		rotationAnimationTargetAngle: initialRotationAngle,
+		turretTargetAngle:            initialRotationAngle,
+		Velocity:                     velocity,
+		RotationSpeed:                rotationSpeed,
+		BoundingBox: Rect{
+			X: 0.0,
+			Y: 0.0,
+			W: size.W,
+			H: size.H,
+		},
+		Health:     health,
+		MaxHealth:  health,
+		Lives:      lives,
+		Controller: controller,
+		SinceShot:  -1.0,
+	}
+}
+
+// the tank collides as it is drawn, rotated around it's centre
+func (tank Tank) Hull() OBB {
+	return NewOBB(tank.BoundingBox, tank.RotationAngle)
+}
+
+/*func (tank Tank) Update(delta float64, r *rand.Rand, playerTankPosition pixel.Vec) (Tank, Bullet) {
+	var bullet Bullet
+
+	return tank, bullet
+}*/
+
+// moves by the intent, MoveX and MoveY are not normalized(moving diagonally is faster, as it always was for the player tank)
+func (tank Tank) Move(moveX float32, moveY float32, delta float32) Tank {
+	tank.BoundingBox.X += moveX * tank.Velocity * delta
+	tank.BoundingBox.Y += moveY * tank.Velocity * delta
+	return tank
+}
+
+// the barrel points there(the heading of the hull, and the turret), the bullets fly there
+func (tank Tank) AimAngle() float32 {
+	return normalizeAngle(tank.RotationAngle + tank.Turret)
+}
+
+/*Turns by the intent: Rotate turns continuously(like the player tank),
+Aim turns towards AimAngle the shorter way(like the enemy tanks), and keeps turning there, until the next aim.
+With tank controls(intent.Turret), Steer turns the hull, and Rotate and Aim turn only the turret.*/
+func (tank *Tank) Turn(intent Intent, delta float32) {
+	if intent.Turret {
+		tank.turnTurret(intent, delta)
+		return
+	}
+	if intent.Aim {
+		tank.rotationAnimationTargetAngle = intent.AimAngle
+	}
+	if intent.Rotate != 0.0 {
+		tank.RotationAngle += intent.Rotate * tank.RotationSpeed * delta
+		tank.rotationAnimationTargetAngle = tank.RotationAngle
+	} else {
+		tank.RotationAngle = turnTowards(tank.RotationAngle, tank.rotationAnimationTargetAngle, tank.RotationSpeed*delta)
+	}
+	tank.RotationAngle = normalizeAngle(tank.RotationAngle)
+}
+
+// the turret keeps aiming at the same angle, while the hull turns under it
+func (tank *Tank) turnTurret(intent Intent, delta float32) {
+	aim := tank.AimAngle()
+	tank.RotationAngle = normalizeAngle(tank.RotationAngle + (intent.Steer * tank.RotationSpeed * delta))
+	tank.rotationAnimationTargetAngle = tank.RotationAngle
+	if intent.Aim {
+		tank.turretTargetAngle = intent.AimAngle
+	}
+	if intent.Rotate != 0.0 {
+		aim += intent.Rotate * tank.RotationSpeed * delta
+		tank.turretTargetAngle = aim
+	} else {
+		aim = turnTowards(aim, tank.turretTargetAngle, tank.RotationSpeed*delta)
+	}
+	tank.Turret = normalizeAngle(aim - tank.RotationAngle)
+}
+
+// turns the hull back to the angle(it could not turn), a separate turret(see turnTurret) keeps it's aim
+func (tank *Tank) turnHullBack(angle float32, turret bool) {
+	aim := tank.AimAngle()
+	tank.RotationAngle = angle
+	if turret {
+		tank.Turret = normalizeAngle(aim - angle)
+	}
+}
+
+// one step from the angle towards the target angle, the shorter way
+func turnTowards(angle float32, target float32, step float32) float32 {
+	difference := AngleDifference(angle, target)
+	if float32(math.Abs(float64(difference))) <= step {
+		return target
+	} else if difference > 0.0 {
+		return angle + step
+	}
+	return angle - step
+}
+
+func normalizeAngle(angle float32) float32 {
+	return float32(math.Mod(float64(angle)+360.0, 360.0)) // or else glitches/bugs are welcome...
+}
+
+/*With tank controls: the throttle(-1 to 1) speeds the tank up towards throttle times it's velocity,
+without the throttle, the friction slows it down.*/
+func (tank *Tank) Accelerate(throttle float32, acceleration float32, friction float32, delta float32) {
+	target := throttle * tank.Velocity
+	rate := acceleration
+	if throttle == 0.0 {
+		rate = friction
+	}
+	if tank.Speed < target {
+		tank.Speed = float32(math.Min(float64(tank.Speed+(rate*delta)), float64(target)))
+	} else {
+		tank.Speed = float32(math.Max(float64(tank.Speed-(rate*delta)), float64(target)))
+	}
+}
+
+/*Returns true, if the tank has lost a life, taking this damage.
+Invulnerable(and shielded) tanks are not damaged.*/
+func (tank *Tank) TakeDamage(damage int) bool {
+	if (tank.invulnerableTimer > 0.0) || (tank.Shield > 0.0) {
+		return false
+	}
+	tank.Health -= damage
+	if tank.Health > 0 {
+		return false
+	}
+	tank.Health = 0
+	tank.Lives -= 1
+	return true
+}
+
+// the tank can not be damaged for a while(invulnerability seconds), after respawning
+func (tank *Tank) Respawn(boundingBox Rect, invulnerability float32) {
+	tank.BoundingBox = boundingBox
+	tank.RotationAngle = 0.0
+	tank.rotationAnimationTargetAngle = 0.0
+	tank.Turret = 0.0
+	tank.turretTargetAngle = 0.0
+	tank.Speed = 0.0
+	tank.Health = tank.MaxHealth
+	tank.Arm(tank.Weapon) // with a full magazine
+	tank.invulnerableTimer = invulnerability
+}
+
+func (tank *Tank) UpdateInvulnerability(delta float32) {
+	if tank.invulnerableTimer > 0.0 {
+		tank.invulnerableTimer -= delta
+	}
+	if tank.Shield > 0.0 {
+		tank.Shield -= delta
+	}
+}
+
+// the tank blinks, while it is invulnerable
+func (tank Tank) Visible() bool {
+	if tank.invulnerableTimer <= 0.0 {
+		return true
+	}
+	return int(tank.invulnerableTimer/PLAYER_TANK_BLINK_TIME)%2 == 0
+}
+
+/*func (tank Tank) Draw(window *sdl.Window) {
+	if tank.alive {
+		matrix := pixel.IM
+		matrix = matrix.Moved(tank.position)
+		// matrix = matrix.Scaled(tank.position, 1.0) // no need to scale, when scale is 1
+		matrix = matrix.Rotated(tank.position, tank.RotationAngle)
+		tank.tankSprite.Draw(window, matrix)
+	}
+}*/
@@ -0,0 +1,142 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAccelerate(t *testing.T) {
+	tank := NewTank(Size{DEFAULT_TANK_WIDTH, DEFAULT_TANK_HEIGHT}, PLAYER_TANK_VELOCITY, TANK_ROTATION_ANGLE, 0.0, 1, 1, &InputController{})
+	// full speed after velocity/acceleration seconds, not faster and not before
+	fullSpeed := int(math.Ceil(float64(PLAYER_TANK_VELOCITY / TANK_ACCELERATION / TEST_TIMESTEP)))
+	last := float32(0.0)
+	for step := 1; step <= fullSpeed+10; step++ {
+		tank.Accelerate(1.0, TANK_ACCELERATION, TANK_FRICTION, TEST_TIMESTEP)
+		if (tank.Speed < last) || (tank.Speed > tank.Velocity) {
+			t.Fatalf("step %d: speed %f after %f, expected it to grow up to %f", step, tank.Speed, last, tank.Velocity)
+		}
+		if (step < fullSpeed) && (tank.Speed >= tank.Velocity) {
+			t.Fatalf("full speed after %d steps, expected %d", step, fullSpeed)
+		}
+		last = tank.Speed
+	}
+	if tank.Speed != tank.Velocity {
+		t.Fatalf("speed %f with the full throttle, expected the velocity of the tank %f", tank.Speed, tank.Velocity)
+	}
+
+	// without the throttle, the friction stops it
+	stop := int(math.Ceil(float64(PLAYER_TANK_VELOCITY / TANK_FRICTION / TEST_TIMESTEP)))
+	for step := 1; step <= stop+10; step++ {
+		tank.Accelerate(0.0, TANK_ACCELERATION, TANK_FRICTION, TEST_TIMESTEP)
+		if (tank.Speed > last) || (tank.Speed < 0.0) {
+			t.Fatalf("step %d: speed %f after %f, expected it to slow down to 0", step, tank.Speed, last)
+		}
+		if (step < stop) && (tank.Speed <= 0.0) {
+			t.Fatalf("stopped after %d steps, expected %d", step, stop)
+		}
+		last = tank.Speed
+	}
+	if tank.Speed != 0.0 {
+		t.Errorf("speed %f without the throttle, expected the tank to stop", tank.Speed)
+	}
+
+	// backwards, and half the throttle
+	for step := 0; step < 100; step++ {
+		tank.Accelerate(-0.5, TANK_ACCELERATION, TANK_FRICTION, TEST_TIMESTEP)
+	}
+	if tank.Speed != -0.5*tank.Velocity {
+		t.Errorf("speed %f with half the throttle backwards, expected %f", tank.Speed, -0.5*tank.Velocity)
+	}
+}
+
+// a world, with tank controls, where the player tank drives
+func tankControlsWorld() (*World, *InputController) {
+	world := quietWorld([]PlayerConfig{{}})
+	world.config.Rules.TankControls = true
+	placeEnemyTank(world, Point{10, 10}, 1) // so that the level is not won
+	return world, world.Players[0].Tank.Controller.(*InputController)
+}
+
+func TestDriving(t *testing.T) {
+	world, controller := tankControlsWorld()
+	tank := &world.Players[0].Tank
+	start := tank.BoundingBox
+	controller.Input.MoveUp = true // forward, to the right(angle 0)
+	world.Step(TEST_TIMESTEP)
+	first := tank.BoundingBox.X - start.X
+	if (first <= 0.0) || (tank.BoundingBox.Y != start.Y) {
+		t.Fatalf("moved from %v to %v, expected the tank to drive to the right", start, tank.BoundingBox)
+	}
+	world.Step(TEST_TIMESTEP)
+	if second := tank.BoundingBox.X - start.X - first; second <= first {
+		t.Errorf("moved %f pixels, then %f, expected the tank to speed up", first, second)
+	}
+	stepFor(world, 0.5)
+	if tank.Speed != world.config.Rules.PlayerTankVelocity {
+		t.Errorf("speed %f after half a second, expected the full speed %f", tank.Speed, world.config.Rules.PlayerTankVelocity)
+	}
+
+	// off the throttle, it rolls on for a while, then stops
+	controller.Input.MoveUp = false
+	position := tank.BoundingBox.X
+	world.Step(TEST_TIMESTEP)
+	if tank.BoundingBox.X <= position {
+		t.Error("the tank stopped at once, without the throttle")
+	}
+	stepFor(world, world.config.Rules.PlayerTankVelocity/world.config.Rules.TankFriction)
+	position = tank.BoundingBox.X
+	world.Step(TEST_TIMESTEP)
+	if (tank.Speed != 0.0) || (tank.BoundingBox.X != position) {
+		t.Errorf("speed %f, the tank is still rolling", tank.Speed)
+	}
+}
+
+func TestTurretAiming(t *testing.T) {
+	// the hull and the turret at 0 degrees
+	tests := []struct {
+		name  string
+		aim   float32
+		turns float32 // -1 anti clock wise, 1 clock wise
+	}{
+		{"a little anti clock wise", 350.0, -1.0},
+		{"a little clock wise", 20.0, 1.0},
+		{"almost behind, clock wise", 170.0, 1.0},
+		{"almost behind, anti clock wise", 190.0, -1.0},
+	}
+	for _, test := range tests {
+		tank := NewTank(Size{DEFAULT_TANK_WIDTH, DEFAULT_TANK_HEIGHT}, PLAYER_TANK_VELOCITY, TANK_ROTATION_ANGLE, 0.0, 1, 1, &InputController{})
+		hull := tank.RotationAngle
+		intent := Intent{Turret: true, Aim: true, AimAngle: test.aim}
+		before := tank.AimAngle()
+		tank.Turn(intent, TEST_TIMESTEP)
+		if turned := AngleDifference(before, tank.AimAngle()); (turned*test.turns <= 0.0) || (float32(math.Abs(float64(turned))) > TANK_ROTATION_ANGLE*TEST_TIMESTEP+0.001) {
+			t.Errorf("%s: the turret turned %f degrees in a step, expected %f at most, the shorter way", test.name, turned, test.turns*TANK_ROTATION_ANGLE*TEST_TIMESTEP)
+		}
+		for step := 0; step < 60; step++ {
+			tank.Turn(Intent{Turret: true}, TEST_TIMESTEP) // it keeps turning there, until the next aim
+		}
+		if math.Abs(float64(AngleDifference(tank.AimAngle(), test.aim))) > 0.001 {
+			t.Errorf("%s: the turret aims at %f, expected %f", test.name, tank.AimAngle(), test.aim)
+		}
+		if tank.RotationAngle != hull {
+			t.Errorf("%s: the hull turned from %f to %f, while aiming the turret", test.name, hull, tank.RotationAngle)
+		}
+	}
+
+	// steering turns the hull under the turret, which keeps it's aim
+	world, controller := tankControlsWorld()
+	tank := &world.Players[0].Tank
+	controller.Input.Aim, controller.Input.AimAngle = true, 224 // 315 degrees
+	stepFor(world, 0.5)
+	if math.Abs(float64(AngleDifference(tank.AimAngle(), 315.0))) > 0.001 || (tank.RotationAngle != 0.0) {
+		t.Fatalf("the turret aims at %f and the hull at %f, expected 315 and 0", tank.AimAngle(), tank.RotationAngle)
+	}
+	controller.Input.MoveRight = true // steering clock wise
+	world.Step(TEST_TIMESTEP)
+	if tank.RotationAngle <= 0.0 {
+		t.Errorf("the hull is at %f, expected it to turn clock wise", tank.RotationAngle)
+	}
+	if math.Abs(float64(AngleDifference(tank.AimAngle(), 315.0))) > 0.001 {
+		t.Errorf("the turret aims at %f while steering, expected it to keep aiming at 315", tank.AimAngle())
+	}
+}
@@ -0,0 +1,165 @@
+// utils.go
+package game
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+func RemoveElementFromBulletSlice(slice []Bullet, index int) []Bullet {
+	// source : https://stackoverflow.com/a/37335777
+	// TODO : How to make it generic, i.e., it can remove an element from a slice of any kind
+	slice[index] = slice[len(slice)-1] // No bounds check = panic(on index out of bounds)
+	return slice[:len(slice)-1]
+}
+
+func RemoveElementFromEnemyTankSlice(slice []Tank, index int) []Tank {
+	// source : https://stackoverflow.com/a/37335777
+	// TODO : How to make it generic, i.e., it can remove an element from a slice of any kind
+	slice[index] = slice[len(slice)-1] // No bounds check = panic(on index out of bounds)
+	return slice[:len(slice)-1]
+}
+
+// removes the first bullet, which hits the hull(see Bullet.Hits), returns true if there was one
+func RemoveBulletHitting(bullets []Bullet, hull OBB) ([]Bullet, bool) {
+	for i := range bullets {
+		if bullets[i].Hits(hull) {
+			return RemoveElementFromBulletSlice(bullets, i), true
+		}
+	}
+	return bullets, false
+}
+
+func RemoveElementFromExplosionSlice(slice []Explosion, index int) []Explosion {
+	// source : https://stackoverflow.com/a/37335777
+	// TODO : How to make it generic, i.e., it can remove an element from a slice of any kind
+	slice[index] = slice[len(slice)-1] // No bounds check = panic(on index out of bounds)
+	return slice[:len(slice)-1]
+}
+
+func DegreeToRadian(angleInDegree float64) float64 {
+	return angleInDegree * (math.Pi / 180.0)
+}
+
+/*Picks a name by the weights(a name with weight 2 is picked twice as often, as a name with weight 1).
+Returns false, if there are no weights(or all of them are 0).*/
+func (world *World) pickByWeight(weights map[string]float32) (string, bool) {
+	names := make([]string, 0, len(weights))
+	var total float32
+	for name, weight := range weights {
+		names = append(names, name)
+		total += weight
+	}
+	if total <= 0.0 {
+		return "", false
+	}
+	sort.Strings(names) // a map has random iteration order, the world has to be deterministic
+	choice := world.r.Float32() * total
+	for _, name := range names {
+		choice -= weights[name]
+		if choice < 0.0 {
+			return name, true
+		}
+	}
+	return names[len(names)-1], true
+}
+
+func IsFinite(value float32) bool {
+	return !math.IsNaN(float64(value)) && !math.IsInf(float64(value), 0)
+}
+
+/*Returns an error for the first float32 field of the struct(by it's json name), which is NaN or infinite.
+A NaN passes every range check(every comparison with it is false), so this goes before them.*/
+func CheckFinite(settings interface{}) error {
+	value := reflect.ValueOf(settings)
+	for index := 0; index < value.NumField(); index++ {
+		field := value.Type().Field(index)
+		if (field.Type.Kind() == reflect.Float32) && !IsFinite(float32(value.Field(index).Float())) {
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "" {
+				name = field.Name
+			}
+			return fmt.Errorf("%s must be a finite number, not %v", name, value.Field(index).Float())
+		}
+	}
+	return nil
+}
+
+func GetRandomFloat32(min float32, max float32, r *rand.Rand) float32 {
+	return min + (r.Float32() * (max - min))
+}
+
+/*Places the enemy tanks, one after the other(every tank is placed away from the ones placed before it).
+The tanks without a free position are left out, they are spawned later(see World.Step).*/
+func (world *World) SetPositionOfEnemyTanks(playerTankHulls []OBB) {
+	placed := world.EnemyTanks[:0]
+	for _, tank := range world.EnemyTanks {
+		boundingBox, ok := world.GetPositionOfOneEnemyTank(tank.BoundingBox, tank.RotationAngle, playerTankHulls)
+		if !ok {
+			world.numOfEnemyTanksSpawned -= 1
+			continue
+		}
+		tank.BoundingBox = boundingBox
+		placed = append(placed, tank)
+		world.enemyTankGrid.Insert(len(placed)-1, tank.Hull().Bounds())
+	}
+	world.EnemyTanks = placed
+}
+
+// random positions tried for a tank, before giving up(a big tank in a crowded arena may not fit anywhere)
+const MAX_SPAWN_ATTEMPTS int = 1000
+
+/*A random free position for a tank turned by the angle, away from the placed enemy tanks(see enemyTankGrid) and the player tanks.
+Returns false, if no free position was found in MAX_SPAWN_ATTEMPTS tries.*/
+func (world *World) GetPositionOfOneEnemyTank(enemyTankBoundingBox Rect, rotationAngle float32, playerTankHulls []OBB) (Rect, bool) {
+	for attempt := 0; attempt < MAX_SPAWN_ATTEMPTS; attempt++ {
+		experimentalTankBoundingBox := Rect{
+			X: world.r.Float32() * world.config.ArenaWidth,
+			Y: world.r.Float32() * world.config.ArenaHeight,
+			W: enemyTankBoundingBox.W,
+			H: enemyTankBoundingBox.H,
+		}
+		if world.ValidPosition(NewOBB(experimentalTankBoundingBox, rotationAngle), playerTankHulls) {
+			return experimentalTankBoundingBox, true
+		}
+	}
+	return enemyTankBoundingBox, false
+}
+
+/*Returns the spawn position of the player tank, if it is free,
+otherwise a random free position(like the enemy tanks get), or the spawn position anyway, if there is none.
+The tank respawns unturned.*/
+func (world *World) GetRespawnPosition(spawnBoundingBox Rect, otherPlayerTankHulls []OBB) Rect {
+	if !world.ValidPosition(NewOBB(spawnBoundingBox, 0.0), otherPlayerTankHulls) {
+		boundingBox, _ := world.GetPositionOfOneEnemyTank(spawnBoundingBox, 0.0, otherPlayerTankHulls)
+		return boundingBox
+	}
+	return spawnBoundingBox
+}
+
+// the position is inside the arena, and free of walls, the placed enemy tanks and these player tanks
+func (world *World) ValidPosition(experimentalTankHull OBB, playerTankHulls []OBB) bool {
+	if world.hitsEnemyTank(experimentalTankHull, nil) {
+		return false
+	}
+	for idx := range playerTankHulls {
+		if experimentalTankHull.Intersects(playerTankHulls[idx]) {
+			return false
+		}
+	}
+	if !world.IsInsideArena(experimentalTankHull.Bounds()) || world.Level.BlocksHull(experimentalTankHull) {
+		return false
+	}
+	return true
+}
+
+func (world *World) IsInsideArena(bounds Rect) bool {
+	return ((bounds.X > 0.0) &&
+		(bounds.Y > 0.0) &&
+		((bounds.X + bounds.W) < world.config.ArenaWidth) &&
+		((bounds.Y + bounds.H) < world.config.ArenaHeight))
+}
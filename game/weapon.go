@@ -0,0 +1,272 @@
+// weapon.go
+package game
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+/*
+
+Every tank shoots with a Weapon: how fast it shoots(the cooldown), how many shots before reloading(the magazine),
+how many projectiles in a shot(a shotgun fans them across the spread), and what the projectiles do:
+shells stop on the first wall, ricochet shells bounce off the walls, and missiles turn towards the nearest target.
+
+The weapons are in WEAPONS, the player tanks and the enemy tanks pick one of them by name(see Rules.PlayerWeapon and Rules.EnemyWeapon).
+The cannon shoots exactly like the tanks always did(one shell at a time, straight ahead, no random spread),
+so it does not use the random numbers of the world, and the levels with it play as before.
+
+*/
+
+type Projectile int
+
+const (
+	PROJECTILE_SHELL    Projectile = iota
+	PROJECTILE_BULLET              // small and fast, of the machine gun and the shotgun
+	PROJECTILE_RICOCHET            // bounces off the walls and the edges of the arena
+	PROJECTILE_MISSILE             // turns towards the nearest target
+)
+
+type Weapon struct {
+	Name       string
+	Projectile Projectile
+	Cooldown   float32 // seconds between the shots
+	Magazine   int     // number of shots before reloading, 0 means it never reloads
+	Reload     float32 // seconds
+	Pellets    int     // projectiles in one shot, 0 means 1
+	Spread     float32 // degrees, the pellets are fanned evenly across it, one projectile deviates randomly within it
+	Damage     int     // to the tanks, 0 means the damage of the rules(Rules.PlayerTankBulletDamage or Rules.EnemyTankBulletDamage)
+	Power      int     // damage to the walls(see BULLET_POWER and HEAVY_BULLET_POWER)
+	Velocity   float32 // times Rules.BulletVelocity, 0 means 1
+	Scale      float32 // of the projectile(of the size of the bullets), 0 means 1
+	Range      float32 // pixels, the projectile is gone after flying this far, 0 means no limit
+	Bounces    int     // off the walls, for ricochet shells
+	Homing     float32 // degrees per second, for missiles
+	Automatic  bool    // keeps shooting while the trigger is held(the renderer asks for a shot on every step, the cooldown limits it)
+}
+
+var WEAPONS []Weapon = []Weapon{
+	{Name: "cannon", Projectile: PROJECTILE_SHELL, Power: BULLET_POWER},
+	{Name: "machineGun", Projectile: PROJECTILE_BULLET, Automatic: true, Cooldown: 0.08, Magazine: 30, Reload: 1.5, Spread: 8.0, Power: BULLET_POWER, Velocity: 1.4, Scale: 0.5},
+	{Name: "ricochet", Projectile: PROJECTILE_RICOCHET, Cooldown: 0.4, Power: BULLET_POWER, Velocity: 0.9, Bounces: 2},
+	{Name: "missile", Projectile: PROJECTILE_MISSILE, Cooldown: 0.8, Magazine: 2, Reload: 2.5, Damage: 2, Power: HEAVY_BULLET_POWER, Velocity: 0.6, Homing: 180.0},
+	{Name: "shotgun", Projectile: PROJECTILE_BULLET, Cooldown: 0.7, Pellets: 5, Spread: 40.0, Power: BULLET_POWER, Scale: 0.5, Range: 200.0},
+	{Name: "artillery", Projectile: PROJECTILE_SHELL, Cooldown: 1.5, Damage: 2, Power: HEAVY_BULLET_POWER, Velocity: 1.6, Scale: 1.2},
+}
+
+const DEFAULT_WEAPON string = "cannon"
+
+func FindWeapon(name string) (Weapon, error) {
+	for _, weapon := range WEAPONS {
+		if weapon.Name == name {
+			return weapon, nil
+		}
+	}
+	return Weapon{}, fmt.Errorf("unknown weapon %q", name)
+}
+
+// the weapon by name, the cannon if there is no such weapon(the rules are validated before, see Rules.Validate)
+func weaponOf(name string) Weapon {
+	weapon, err := FindWeapon(name)
+	if err != nil {
+		weapon, _ = FindWeapon(DEFAULT_WEAPON)
+	}
+	return weapon
+}
+
+/*Counts down the cooldown and the reload of the weapon of the tank.
+A magazine is full, when the tank gets the weapon(see Tank.Arm).*/
+func (tank *Tank) UpdateWeapon(delta float32) {
+	if tank.RapidFire > 0.0 {
+		tank.RapidFire -= delta
+		delta *= RAPID_FIRE_FACTOR
+	}
+	if tank.cooldown > 0.0 {
+		tank.cooldown -= delta
+	}
+	if tank.Reloading > 0.0 {
+		tank.Reloading -= delta
+		if tank.Reloading <= 0.0 {
+			tank.Reloading = 0.0
+			tank.Ammo = tank.Weapon.Magazine
+		}
+	}
+}
+
+// the tank gets the weapon, with a full magazine
+func (tank *Tank) Arm(weapon Weapon) {
+	tank.Weapon = weapon
+	tank.Ammo = weapon.Magazine
+	tank.Reloading = 0.0
+	tank.cooldown = 0.0
+}
+
+// the weapon has cooled down, and it is not reloading
+func (tank Tank) CanShoot() bool {
+	return (tank.cooldown <= 0.0) && (tank.Reloading <= 0.0)
+}
+
+/*Shoots the weapon of the tank: the cooldown starts, a shot is taken from the magazine(reloading, if it is empty),
+and the projectiles fly from the centre of the tank, where it aims(see AimAngle).
+The random numbers are used only by weapons with a random spread.*/
+func (tank *Tank) Shoot(bulletSize Size, velocity float32, r *rand.Rand) []Bullet {
+	weapon := tank.Weapon
+	tank.cooldown = weapon.Cooldown
+	if weapon.Magazine > 0 {
+		tank.Ammo -= 1
+		if tank.Ammo <= 0 {
+			tank.Ammo = 0
+			tank.Reloading = weapon.Reload
+		}
+	}
+	velocity = weapon.velocityOf(velocity)
+	if weapon.Scale > 0.0 {
+		bulletSize = Size{bulletSize.W * weapon.Scale, bulletSize.H * weapon.Scale}
+	}
+	pellets := weapon.Pellets
+	if pellets < 1 {
+		pellets = 1
+	}
+	bullets := make([]Bullet, pellets)
+	for index := range bullets {
+		angle := tank.AimAngle()
+		if pellets > 1 {
+			angle += (weapon.Spread * float32(index) / float32(pellets-1)) - (weapon.Spread / 2.0)
+		} else if weapon.Spread > 0.0 {
+			angle += GetRandomFloat32(-weapon.Spread/2.0, weapon.Spread/2.0, r)
+		}
+		bullets[index] = Bullet{
+			Velocity: velocity,
+			Power:    weapon.Power,
+			BoundingBox: Rect{
+				X: tank.BoundingBox.X + (tank.BoundingBox.W / 2.0) - (bulletSize.W / 2.0), // shooting from the centre of the tank, and putting the bullet's centre at the centre of the tank
+				Y: tank.BoundingBox.Y + (tank.BoundingBox.H / 2.0) - (bulletSize.H / 2.0),
+				W: bulletSize.W,
+				H: bulletSize.H,
+			},
+			RotationAngle: normalizeAngle(angle),
+			Projectile:    weapon.Projectile,
+			Damage:        weapon.Damage,
+			Range:         weapon.Range,
+			Bounces:       weapon.Bounces,
+			Homing:        weapon.Homing,
+		}
+	}
+	return bullets
+}
+
+// pixels per second, of the projectiles of the weapon(velocity is Rules.BulletVelocity)
+func (weapon Weapon) velocityOf(velocity float32) float32 {
+	if weapon.Velocity > 0.0 {
+		return velocity * weapon.Velocity
+	}
+	return velocity
+}
+
+//==============PROJECTILES==============
+
+// the damage of the bullet to a tank, the damage of the rules(base), if the weapon does not have it's own
+func (bullet Bullet) DamageOr(base int) int {
+	if bullet.Damage > 0 {
+		return bullet.Damage
+	}
+	return base
+}
+
+// the bullet has flown as far as it's range
+func (bullet Bullet) Spent() bool {
+	return (bullet.Range > 0.0) && (bullet.travelled >= bullet.Range)
+}
+
+/*Mirrors the direction of the bullet(horizontally, vertically or both), and takes it back to where it's nose
+was before the last Update, outside of the wall it has hit.*/
+func (bullet *Bullet) bounce(reflectX bool, reflectY bool) {
+	nose := bullet.NosePosition()
+	bullet.BoundingBox.X -= nose.X - bullet.lastNose.X
+	bullet.BoundingBox.Y -= nose.Y - bullet.lastNose.Y
+	if reflectX {
+		bullet.RotationAngle = 180.0 - bullet.RotationAngle
+	}
+	if reflectY {
+		bullet.RotationAngle = -bullet.RotationAngle
+	}
+	bullet.RotationAngle = normalizeAngle(bullet.RotationAngle)
+	bullet.lastNose = bullet.NosePosition()
+	bullet.Bounces -= 1
+}
+
+// bounces the bullet off the wall, it's nose has flown into(the side of the wall it has crossed)
+func (world *World) bounceOffWall(bullet *Bullet) {
+	column, row := world.Level.Cell(bullet.NosePosition())
+	lastColumn, lastRow := world.Level.Cell(bullet.lastNose)
+	reflectX := (column != lastColumn) && world.Level.TileAt(column, lastRow).BlocksBullet()
+	reflectY := (row != lastRow) && world.Level.TileAt(lastColumn, row).BlocksBullet()
+	if !reflectX && !reflectY { // into a corner(or from inside of the wall), straight back
+		reflectX, reflectY = true, true
+	}
+	bullet.bounce(reflectX, reflectY)
+}
+
+/*Bounces the bullet off the edges of the arena, it is flying out of.
+Returns false, if it is not flying towards any of them(it can not come back in).*/
+func (world *World) bounceOffArena(bullet *Bullet) bool {
+	box := bullet.BoundingBox
+	radians := DegreeToRadian(float64(bullet.RotationAngle))
+	dx, dy := math.Cos(radians), math.Sin(radians)
+	reflectX := ((box.X <= 0.0) && (dx < 0.0)) || ((box.X+box.W >= world.config.ArenaWidth) && (dx > 0.0))
+	reflectY := ((box.Y <= 0.0) && (dy < 0.0)) || ((box.Y+box.H >= world.config.ArenaHeight) && (dy > 0.0))
+	if !reflectX && !reflectY {
+		return false
+	}
+	bullet.bounce(reflectX, reflectY)
+	return true
+}
+
+/*Turns the missiles towards the nearest target of the shooter(the index of the player, -1 for the enemy tanks),
+at most Homing degrees per second.*/
+func (world *World) steerMissiles(bullets []Bullet, shooter int, dt float32) {
+	for index := range bullets {
+		bullet := &bullets[index]
+		if bullet.Homing <= 0.0 {
+			continue
+		}
+		nose := bullet.NosePosition()
+		target, found := world.nearestTarget(nose, shooter)
+		if found {
+			bullet.RotationAngle = normalizeAngle(turnTowards(bullet.RotationAngle, angleTo(nose, target), bullet.Homing*dt))
+		}
+	}
+}
+
+/*The centre of the nearest tank, the shooter can hurt: the player tanks for the enemy tanks(shooter -1),
+the enemy tanks for the players, and the other players in versus or with friendly fire.
+Returns false, if there is nothing to hit.*/
+func (world *World) nearestTarget(point Point, shooter int) (Point, bool) {
+	if shooter < 0 {
+		player := world.nearestPlayer(point)
+		if player == nil {
+			return Point{}, false
+		}
+		return player.Tank.BoundingBox.Centre(), true
+	}
+	var nearest Point
+	nearestDistance := float32(math.Inf(1))
+	consider := func(tank *Tank) {
+		centre := tank.BoundingBox.Centre()
+		if d := distance(point, centre); d < nearestDistance {
+			nearest, nearestDistance = centre, d
+		}
+	}
+	for index := range world.EnemyTanks {
+		consider(&world.EnemyTanks[index])
+	}
+	if (world.config.Mode == MODE_VERSUS) || world.config.FriendlyFire {
+		for index := range world.Players {
+			if (index != shooter) && !world.Players[index].Lost {
+				consider(&world.Players[index].Tank)
+			}
+		}
+	}
+	return nearest, nearestDistance < float32(math.Inf(1))
+}
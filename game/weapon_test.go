@@ -0,0 +1,237 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// a tank at (200, 200), aiming at the angle, with the weapon
+func armedTank(t *testing.T, name string, angle float32) Tank {
+	weapon, err := FindWeapon(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tank := NewTank(Size{DEFAULT_TANK_WIDTH, DEFAULT_TANK_HEIGHT}, PLAYER_TANK_VELOCITY, TANK_ROTATION_ANGLE, angle, 1, 1, &InputController{})
+	tank.BoundingBox.X, tank.BoundingBox.Y = 200, 200
+	tank.Arm(weapon)
+	return tank
+}
+
+func shoot(tank *Tank, r *rand.Rand) []Bullet {
+	return tank.Shoot(Size{DEFAULT_BULLET_WIDTH, DEFAULT_BULLET_HEIGHT}, BULLET_VELOCITY, r)
+}
+
+func TestCooldown(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tank := armedTank(t, "machineGun", 0.0)
+	cooldown := tank.Weapon.Cooldown
+	if !tank.CanShoot() {
+		t.Fatal("a new weapon can not shoot")
+	}
+	shoot(&tank, r)
+	tank.UpdateWeapon(cooldown / 2.0)
+	if tank.CanShoot() {
+		t.Error("the weapon can shoot again, before it's cooldown")
+	}
+	tank.UpdateWeapon(cooldown / 2.0)
+	if !tank.CanShoot() {
+		t.Error("the weapon can not shoot, after it's cooldown")
+	}
+
+	// the trigger held for a second, at 100 steps per second
+	tank = armedTank(t, "machineGun", 0.0)
+	shots := 0
+	for step := 0; step < 100; step++ {
+		tank.UpdateWeapon(0.01)
+		if tank.CanShoot() {
+			shoot(&tank, r)
+			shots += 1
+		}
+	}
+	if expected := int(math.Ceil(float64(1.0 / cooldown))); (shots < expected-1) || (shots > expected) {
+		t.Errorf("%d shots in a second, expected about %d(the cooldown is %f seconds)", shots, expected, cooldown)
+	}
+}
+
+func TestMagazineAndReload(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tank := armedTank(t, "machineGun", 0.0)
+	weapon := tank.Weapon
+	if tank.Ammo != weapon.Magazine {
+		t.Fatalf("a new weapon has %d shots, expected a full magazine of %d", tank.Ammo, weapon.Magazine)
+	}
+	for shot := 1; shot <= weapon.Magazine; shot++ {
+		if !tank.CanShoot() {
+			t.Fatalf("can not shoot shot %d of the magazine of %d", shot, weapon.Magazine)
+		}
+		shoot(&tank, r)
+		if tank.Ammo != weapon.Magazine-shot {
+			t.Fatalf("%d shots left after %d, expected %d", tank.Ammo, shot, weapon.Magazine-shot)
+		}
+		tank.UpdateWeapon(weapon.Cooldown)
+	}
+	if (tank.Reloading <= 0.0) || tank.CanShoot() {
+		t.Fatalf("an empty magazine is not reloading(reloading %f)", tank.Reloading)
+	}
+
+	tank.UpdateWeapon(weapon.Reload - weapon.Cooldown - 0.1)
+	if tank.CanShoot() || (tank.Ammo != 0) {
+		t.Errorf("reloaded %d shots, before the reload time", tank.Ammo)
+	}
+	tank.UpdateWeapon(0.11)
+	if !tank.CanShoot() || (tank.Ammo != weapon.Magazine) || (tank.Reloading != 0.0) {
+		t.Errorf("after reloading: %d shots, reloading %f, expected a full magazine of %d", tank.Ammo, tank.Reloading, weapon.Magazine)
+	}
+
+	// without a magazine, it never reloads
+	cannon := armedTank(t, "cannon", 0.0)
+	for shot := 0; shot < 100; shot++ {
+		shoot(&cannon, r)
+	}
+	if cannon.Reloading != 0.0 {
+		t.Error("the cannon is reloading")
+	}
+}
+
+func TestShotgunSpread(t *testing.T) {
+	tank := armedTank(t, "shotgun", 90.0)
+	weapon := tank.Weapon
+	pellets := shoot(&tank, rand.New(rand.NewSource(1)))
+	if len(pellets) != weapon.Pellets {
+		t.Fatalf("%d pellets, expected %d", len(pellets), weapon.Pellets)
+	}
+	// evenly across the spread, centred on the aim
+	gap := weapon.Spread / float32(weapon.Pellets-1)
+	for index, pellet := range pellets {
+		expected := 90.0 - (weapon.Spread / 2.0) + (gap * float32(index))
+		if math.Abs(float64(AngleDifference(pellet.RotationAngle, expected))) > 0.001 {
+			t.Errorf("pellet %d flies at %f degrees, expected %f", index, pellet.RotationAngle, expected)
+		}
+		if pellet.BoundingBox.Centre() != tank.BoundingBox.Centre() {
+			t.Errorf("pellet %d starts at %v, expected the centre of the tank %v", index, pellet.BoundingBox.Centre(), tank.BoundingBox.Centre())
+		}
+	}
+}
+
+func TestRicochet(t *testing.T) {
+	// steel down column 10(x from 250 to 275), and across row 10(y from 250 to 275)
+	rows := wallRows(10, 0, 9, 'S')
+	rows[10] = "SSSSSSSSSSSSSSSSSSSS"
+	world := levelWorld(t, rows...)
+	tests := []struct {
+		name   string
+		centre Point
+		angle  float32
+		result float32
+	}{
+		{"straight into the wall", Point{230, 137.5}, 0.0, 180.0},
+		{"into the side of the wall", Point{230, 130}, 30.0, 150.0},
+		{"into the top of the wall", Point{130, 230}, 60.0, 300.0},
+	}
+	for _, test := range tests {
+		bullet := Bullet{
+			Velocity:      600, // 10 pixels in a step, the nose gets into the wall
+			BoundingBox:   Rect{X: test.centre.X - 15, Y: test.centre.Y - 4, W: 30, H: 8},
+			RotationAngle: test.angle,
+			Power:         BULLET_POWER,
+			Bounces:       2,
+		}
+		bullet.Update(TEST_TIMESTEP)
+		if !world.Level.BlocksBullet(bullet.NosePosition()) {
+			t.Fatalf("%s: the nose %v is not in the wall", test.name, bullet.NosePosition())
+		}
+		bullets := world.removeBulletsHittingWalls([]Bullet{bullet})
+		if len(bullets) != 1 {
+			t.Fatalf("%s: the ricochet shell stopped on the wall", test.name)
+		}
+		if math.Abs(float64(AngleDifference(bullets[0].RotationAngle, test.result))) > 0.001 {
+			t.Errorf("%s: flies at %f degrees after the bounce, expected %f", test.name, bullets[0].RotationAngle, test.result)
+		}
+		if bullets[0].Bounces != 1 {
+			t.Errorf("%s: %d bounces left, expected 1", test.name, bullets[0].Bounces)
+		}
+		if world.Level.BlocksBullet(bullets[0].NosePosition()) {
+			t.Errorf("%s: the nose %v is still in the wall, after the bounce", test.name, bullets[0].NosePosition())
+		}
+	}
+
+	// out of bounces, it stops on the wall like a shell(and damages it)
+	brick := levelWorld(t, wallRows(10, 0, LEVEL_ROWS-1, 'B')...)
+	bullet := Bullet{Velocity: 600, BoundingBox: Rect{X: 215, Y: 133.5, W: 30, H: 8}, Power: BULLET_POWER, Projectile: PROJECTILE_RICOCHET}
+	bullet.Update(TEST_TIMESTEP)
+	if bullets := brick.removeBulletsHittingWalls([]Bullet{bullet}); len(bullets) != 0 {
+		t.Error("a ricochet shell without bounces left did not stop on the wall")
+	}
+	if !brick.Level.Damaged(10, 5) {
+		t.Error("the ricochet shell did not damage the wall")
+	}
+}
+
+func TestShotgunRange(t *testing.T) {
+	world := levelWorld(t)
+	tank := armedTank(t, "shotgun", 0.0)
+	tank.Weapon.Pellets, tank.Weapon.Spread = 1, 0.0 // one pellet, straight to the right
+	bullets := shoot(&tank, nil)
+	bullets[0].Velocity = 600 // 10 pixels in a step
+	steps := int(tank.Weapon.Range / 10)
+	for step := 1; step <= steps; step++ {
+		bullets[0].Update(TEST_TIMESTEP)
+		bullets = world.removeBulletsOutOfArena(bullets)
+		if (step < steps) && (len(bullets) == 0) {
+			t.Fatalf("the pellet is gone after %d pixels, it's range is %f", step*10, tank.Weapon.Range)
+		}
+	}
+	if len(bullets) != 0 {
+		t.Errorf("the pellet is still flying, after it's range of %f pixels", tank.Weapon.Range)
+	}
+
+	// without a range, it flies on(to the edge of the arena, 255 pixels away)
+	cannon := armedTank(t, "cannon", 0.0)
+	bullets = shoot(&cannon, nil)
+	for step := 0; step < steps+2; step++ {
+		bullets[0].Velocity = 600
+		bullets[0].Update(TEST_TIMESTEP)
+		if bullets = world.removeBulletsOutOfArena(bullets); len(bullets) == 0 {
+			t.Fatalf("the shell is gone after %d pixels, still in the arena", (step+1)*10)
+		}
+	}
+}
+
+func TestMissileSteering(t *testing.T) {
+	world := quietWorld([]PlayerConfig{{}})
+	player := world.Players[0].Tank.BoundingBox
+	placeEnemyTank(world, Point{player.X, player.Y - 150}, 1) // straight above
+	tank := armedTank(t, "missile", 0.0)
+	tank.BoundingBox = player
+	missiles := shoot(&tank, nil)
+	world.steerMissiles(missiles, 0, 0.1)
+	expected := normalizeAngle(-tank.Weapon.Homing * 0.1) // it turns up, at most Homing degrees per second
+	if math.Abs(float64(AngleDifference(missiles[0].RotationAngle, expected))) > 0.001 {
+		t.Errorf("the missile flies at %f degrees, expected %f", missiles[0].RotationAngle, expected)
+	}
+}
+
+// the cannon shoots like the tanks always did, so the random numbers of the world are the same as before
+func TestCannonDrawsNoRandomNumbers(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tank := armedTank(t, "cannon", 45.0)
+	for shot := 0; shot < 10; shot++ {
+		bullets := shoot(&tank, r)
+		if (len(bullets) != 1) || (bullets[0].RotationAngle != 45.0) {
+			t.Fatalf("the cannon shot %d bullets, at %f degrees", len(bullets), bullets[0].RotationAngle)
+		}
+		tank.UpdateWeapon(1.0)
+	}
+	if r.Int63() != rand.New(rand.NewSource(1)).Int63() {
+		t.Error("the cannon used random numbers")
+	}
+
+	// but the machine gun does, for it's random spread
+	r = rand.New(rand.NewSource(1))
+	machineGun := armedTank(t, "machineGun", 45.0)
+	shoot(&machineGun, r)
+	if r.Int63() == rand.New(rand.NewSource(1)).Int63() {
+		t.Error("the machine gun did not use random numbers")
+	}
+}
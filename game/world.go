@@ -0,0 +1,637 @@
+// world.go
+package game
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+/*
+
+The World owns every game object, and all of the game rules.
+It does not know anything about sdl, textures, sounds or the keyboard, so it can run
+without a display(tests, bots, servers...).
+The renderer(see main.go) only reads the exported fields of the world, and draws them,
+and plays sounds for the events, happened in the last step.
+
+The world is deterministic: all of the randomness comes from the seed, and all of the timers
+run on the simulated time(the dt of Step), so two worlds with the same seed, stepped with the
+same dts and inputs, will always be in the same state.
+
+*/
+
+type State int
+
+const (
+	STATE_RUNNING State = iota
+	STATE_PLAYER_WON
+	STATE_PLAYER_LOST
+)
+
+type Mode int
+
+const (
+	MODE_COOP   Mode = iota // the players fight the enemy tanks together
+	MODE_VERSUS             // the players fight each other, there are no enemy tanks
+)
+
+var MODE_NAMES map[Mode]string = map[Mode]string{
+	MODE_COOP:   "coop",
+	MODE_VERSUS: "versus",
+}
+
+func FindMode(name string) (Mode, error) {
+	for mode, modeName := range MODE_NAMES {
+		if modeName == name {
+			return mode, nil
+		}
+	}
+	return MODE_COOP, fmt.Errorf("unknown game mode %q", name)
+}
+
+type Event int
+
+const (
+	EVENT_SHOOT Event = iota
+	EVENT_EXPLOSION
+	EVENT_WALL_DESTROYED
+)
+
+// Input is what the player presses in one step, it is turned into an Intent by Input.Intent(see controller.go)
+type Input struct {
+	RotateAntiClockWise bool
+	RotateClockWise     bool
+	MoveUp              bool
+	MoveLeft            bool
+	MoveDown            bool
+	MoveRight           bool
+	Shoot               bool // only for one step, the player tank should not shoot continuously
+
+	// analog input(gamepads), quantized so that the replays are exact
+	MoveX    int8  // -127(left) to 127(right), added to the move keys
+	MoveY    int8  // -127(up) to 127(down)
+	Aim      bool  // turn towards AimAngle
+	AimAngle uint8 // INPUT_AIM_STEPS steps make a full circle
+}
+
+// everything that belongs to one player
+type Player struct {
+	Tank    Tank
+	Bullets []Bullet
+	Score   int
+	Lost    bool  // set, when the player tank has lost all of it's lives, the tank is not drawn anymore
+	Stats   Stats // of this level(see score.go)
+	Streak  int   // kills in the current kill streak
+
+	spawnBoundingBox Rect
+	streakTimer      float32 // seconds left, until the kill streak ends
+	velocity         Point   // pixels per second, in the last step(the enemy tanks aim ahead of the player tank)
+}
+
+type World struct {
+	Players          []Player
+	EnemyTanks       []Tank
+	EnemyTankBullets []Bullet
+	Explosions       []Explosion
+	PowerUps         []PowerUp // dropped by the destroyed enemy tanks(see powerup.go)
+	Frozen           float32   // seconds left, until the enemy tanks move again
+	Fortified        float32   // seconds left, until the fortified walls are brick walls again
+	Level            *Level
+	State            State
+	Winner           int     // in versus, index of the player who won, -1 for a draw
+	Events           []Event // events happened in the last step, the renderer plays sounds for them
+
+	Seed  int64
+	Ticks int     // number of steps, taken so far
+	Time  float32 // simulated seconds, so far(for the time bonus)
+
+	config                 Config
+	r                      *rand.Rand
+	numOfEnemyTanksSpawned int
+	enemyTankSpawnTimer    float32
+	fortifiedTiles         []int // indices of the brick walls, which have been turned into steel
+
+	//==============BROADPHASE(see spatial.go)==============
+	enemyTankGrid     *SpatialHash // the placed enemy tanks, by their index in EnemyTanks
+	enemyBulletGrid   bulletGrid
+	playerBulletGrids []bulletGrid
+	nearby            []int // reused by the queries of enemyTankGrid
+}
+
+func NewWorld(config Config, seed int64) *World {
+	r := rand.New(rand.NewSource(seed))
+	if config.Difficulty.Name == "" {
+		config.Difficulty = DIFFICULTIES[DEFAULT_DIFFICULTY]
+	}
+	if len(config.Players) == 0 {
+		config.Players = []PlayerConfig{{}}
+	}
+	if config.Rules == (Rules{}) {
+		config.Rules = DefaultRules()
+	}
+	world := &World{
+		Seed:   seed,
+		config: config,
+		r:      r,
+		State:  STATE_RUNNING,
+		Winner: -1,
+	}
+	if config.Level == nil {
+		world.Level = NewEmptyLevel()
+	} else {
+		world.Level = config.Level.Clone() // the walls of the level will be damaged
+	}
+	world.enemyTankGrid = NewSpatialHash(config.ArenaWidth, config.ArenaHeight, SPATIAL_CELL_SIZE)
+	world.enemyBulletGrid.hash = NewSpatialHash(config.ArenaWidth, config.ArenaHeight, SPATIAL_CELL_SIZE)
+	world.playerBulletGrids = make([]bulletGrid, len(config.Players))
+	for index := range world.playerBulletGrids {
+		world.playerBulletGrids[index].hash = NewSpatialHash(config.ArenaWidth, config.ArenaHeight, SPATIAL_CELL_SIZE)
+	}
+
+	//==============PLAYER TANKS==============
+	world.Players = make([]Player, len(config.Players))
+	for index, playerConfig := range config.Players {
+		lives := config.Rules.PlayerTankLives
+		if playerConfig.Lives > 0 {
+			lives = playerConfig.Lives
+		}
+		controller := playerConfig.Controller
+		if controller == nil {
+			controller = &InputController{} // standing still
+		}
+		tank := NewTank(config.PlayerTankSize, config.Rules.PlayerTankVelocity, config.Rules.TankRotationSpeed, 0.0, config.Rules.PlayerTankMaxHealth, lives, controller)
+		tank.Arm(weaponOf(config.Rules.PlayerWeapon))
+		// the player tanks are spread evenly across the middle of the arena(one player is exactly at the centre)
+		tank.BoundingBox.X = (config.ArenaWidth * float32(index+1) / float32(len(config.Players)+1)) - (config.PlayerTankSize.W / 2.0)
+		tank.BoundingBox.Y = (config.ArenaHeight / 2.0) - (config.PlayerTankSize.H / 2.0)
+		if !world.ValidPosition(tank.Hull(), world.playerTankHulls(-1)) { // the spawn position is not free(if no position is, it stays there)
+			tank.BoundingBox, _ = world.GetPositionOfOneEnemyTank(tank.BoundingBox, tank.RotationAngle, world.playerTankHulls(-1))
+		}
+		world.Players[index] = Player{
+			Tank:             tank,
+			Score:            playerConfig.Score,
+			spawnBoundingBox: tank.BoundingBox,
+		}
+	}
+
+	//==============ENEMY TANKS==============
+	if config.Mode == MODE_VERSUS {
+		return world
+	}
+	x := 2 + r.Intn(config.LevelSettings.MaxNumOfEnemyTanks/2) // Initially random num.of tanks will be alive(halving it so that the generated random no. is not too much), let us make at least 2 tanks alive at first
+	world.EnemyTanks = make([]Tank, x)
+	for i := 0; i < x; i++ { // first x no.of tanks will be alive
+		world.EnemyTanks[i] = world.newEnemyTank()
+	}
+	world.numOfEnemyTanksSpawned = x
+	world.SetPositionOfEnemyTanks(world.playerTankHulls(-1))
+
+	return world
+}
+
+func (world *World) Config() Config {
+	return world.config
+}
+
+// an enemy tank of one of the classes of the level(see enemy.go)
+func (world *World) newEnemyTank() Tank {
+	settings := world.config.LevelSettings
+	class := world.newEnemyClass()
+	rotationAngle := world.r.Float32() * 360.0
+	crazy := world.r.Float32() < settings.CrazyTankChance
+	var noUpdateTime float32
+	if world.config.Rules.CrazyTanks || crazy {
+		noUpdateTime = GetRandomFloat32(0.0, 0.5, world.r)
+	} else {
+		noUpdateTime = GetRandomFloat32(settings.EnemyTankMinNoUpdateTime, settings.EnemyTankMaxNoUpdateTime, world.r)
+	}
+	if class.ReactionTime > 0.0 {
+		noUpdateTime *= class.ReactionTime
+	}
+	health := settings.EnemyTankHealth
+	if class.Health > 0 {
+		health = class.Health
+	}
+	if health <= 0 {
+		health = 1
+	}
+	var behaviour Behaviour
+	if class.Behaviour != "" {
+		behaviour = BEHAVIOURS[class.Behaviour]()
+	} else {
+		behaviour = world.newBehaviour(settings.EnemyBehaviours)
+	}
+	velocity := settings.EnemyTankVelocity
+	if class.Speed > 0.0 {
+		velocity *= class.Speed
+	}
+	size := world.config.EnemyTankSize
+	if class.Scale > 0.0 {
+		size = Size{size.W * class.Scale, size.H * class.Scale}
+	}
+	weapon := world.config.Rules.EnemyWeapon
+	if class.Weapon != "" {
+		weapon = class.Weapon
+	}
+	controller := NewAIController(behaviour, noUpdateTime*world.config.Difficulty.ReactionTime)
+	tank := NewTank(size, velocity, world.config.Rules.TankRotationSpeed, rotationAngle, health, 1, controller)
+	tank.Arm(weaponOf(weapon))
+	tank.Class = class
+	return tank
+}
+
+func (world *World) newExplosion(boundingBox Rect) Explosion {
+	return NewExplosion(boundingBox, world.config.ExplosionFrames, world.config.Rules.ExplosionLifeSpan)
+}
+
+// hulls of the player tanks, which have not lost yet, except the player tank at this index(-1 for none)
+func (world *World) playerTankHulls(except int) []OBB {
+	var hulls []OBB
+	for index := range world.Players {
+		if (index != except) && !world.Players[index].Lost {
+			hulls = append(hulls, world.Players[index].Tank.Hull())
+		}
+	}
+	return hulls
+}
+
+// number of players, who have not lost yet
+func (world *World) playersLeft() int {
+	left := 0
+	for index := range world.Players {
+		if !world.Players[index].Lost {
+			left += 1
+		}
+	}
+	return left
+}
+
+// the player tank nearest to the point, which has not lost yet, nil if every player has lost
+func (world *World) nearestPlayer(point Point) *Player {
+	var nearest *Player
+	for index := range world.Players {
+		player := &world.Players[index]
+		if player.Lost {
+			continue
+		}
+		if (nearest == nil) || (distance(point, player.Tank.BoundingBox.Centre()) < distance(point, nearest.Tank.BoundingBox.Centre())) {
+			nearest = player
+		}
+	}
+	return nearest
+}
+
+// every tank is moved by it's controller(see controller.go)
+func (world *World) Step(dt float32) {
+	world.Events = world.Events[:0]
+	if world.State != STATE_RUNNING {
+		return
+	}
+	world.Ticks += 1
+	world.Time += dt
+
+	if world.config.Mode == MODE_VERSUS {
+		//==============CHECKING WHETHER A PLAYER HAS WON==============
+		if (world.playersLeft() <= 1) && (len(world.Explosions) == 0) /*waiting for the explosion of the player tank*/ {
+			world.State = STATE_PLAYER_WON
+			for index := range world.Players {
+				if !world.Players[index].Lost {
+					world.Winner = index
+				}
+			}
+			return
+		}
+	} else {
+		//==============CHECKING WHETHER PLAYERS HAVE LOST==============
+		if (world.playersLeft() == 0) && (len(world.Explosions) == 0) /*waiting for the explosion of the player tank*/ {
+			world.State = STATE_PLAYER_LOST
+			return
+		}
+
+		//==============CHECKING WHETHER PLAYERS HAVE WON==============
+		if (len(world.EnemyTanks) == 0) /*if all the tanks has been destroyed by the players, and*/ &&
+			(world.numOfEnemyTanksSpawned == world.config.LevelSettings.MaxNumOfEnemyTanks) /*if all the tanks has been spawned*/ {
+			world.State = STATE_PLAYER_WON
+			world.awardBonuses()
+			return
+		}
+
+		//==============SPAWNING NEW ENEMY TANKS==============
+		world.enemyTankSpawnTimer += dt
+		if (world.enemyTankSpawnTimer >= world.config.LevelSettings.EnemySpawnOffTime) && (world.numOfEnemyTanksSpawned < world.config.LevelSettings.MaxNumOfEnemyTanks) {
+			tank := world.newEnemyTank()
+			boundingBox, ok := world.GetPositionOfOneEnemyTank(tank.BoundingBox, tank.RotationAngle, world.playerTankHulls(-1))
+			if ok { // otherwise there is no room for it now, it is tried again on the next spawn
+				tank.BoundingBox = boundingBox
+				world.EnemyTanks = append(world.EnemyTanks, tank)
+				world.enemyTankGrid.Insert(len(world.EnemyTanks)-1, tank.Hull().Bounds())
+				world.numOfEnemyTanksSpawned += 1
+			}
+			world.enemyTankSpawnTimer = 0.0
+		}
+	}
+
+	world.updateEnemyTanks(dt)
+
+	//==============UPDATING ENEMY TANK BULLETS==============
+	world.steerMissiles(world.EnemyTankBullets, -1, dt)
+	for index := range world.EnemyTankBullets {
+		world.EnemyTankBullets[index].Update(dt)
+	}
+	world.EnemyTankBullets = world.removeBulletsHittingWalls(world.EnemyTankBullets)
+
+	//==============OPTIMIZATON(removing the bullets, which are out of the arena)==============
+	// range over slice will not work, as:
+	// for i, _ := range ...{...}, here the maximum value of i is the length of the slice
+	// i is initialized with length of the slice, but it doesn't assert new value of that length, when the length of that slice changes
+	// for i := 0; i < len(...); i++ {...} in this kind of loop the ;len(...); condition is always checked
+	for index := range world.Players {
+		world.Players[index].Bullets = world.removeBulletsOutOfArena(world.Players[index].Bullets)
+	}
+	world.EnemyTankBullets = world.removeBulletsOutOfArena(world.EnemyTankBullets)
+
+	world.enemyBulletGrid.reset(&world.EnemyTankBullets)
+	world.damagePlayerTanks(dt)
+
+	world.updatePlayerTanks(dt)
+
+	//==============UPDATING PLAYER TANK BULLETS==============
+	for index := range world.Players {
+		player := &world.Players[index]
+		world.steerMissiles(player.Bullets, index, dt)
+		for i := range player.Bullets {
+			player.Bullets[i].Update(dt)
+		}
+		player.Bullets = world.removeBulletsHittingWalls(player.Bullets)
+		world.playerBulletGrids[index].reset(&player.Bullets)
+	}
+
+	world.damageEnemyTanks()
+
+	if (world.config.Mode == MODE_VERSUS) || world.config.FriendlyFire {
+		world.damagePlayerTanksByPlayers()
+	}
+
+	world.updatePowerUps(dt)
+	world.updateStreaks(dt)
+
+	//==============REMOVING DIED EXPLOSION ANIMATIONS==============
+	for i := 0; i < len(world.Explosions); i++ {
+		if world.Explosions[i].Died {
+			world.Explosions = RemoveElementFromExplosionSlice(world.Explosions, i)
+		}
+	}
+
+	//==============UPDATING EXPLOSION ANIMATIONS==============
+	for index := range world.Explosions {
+		world.Explosions[index].Update(dt)
+	}
+}
+
+func (world *World) updateEnemyTanks(dt float32) {
+	if world.Frozen > 0.0 {
+		return // the controllers are not asked either, the enemy tanks do not even think
+	}
+	for index := range world.EnemyTanks {
+		lastBounds := world.EnemyTanks[index].Hull().Bounds()
+		if world.updateTank(&world.EnemyTanks[index], dt) {
+			world.EnemyTankBullets = append(world.EnemyTankBullets, world.EnemyTanks[index].Shoot(world.config.BulletSize, world.config.Rules.BulletVelocity, world.r)...)
+			world.Events = append(world.Events, EVENT_SHOOT)
+		}
+		world.enemyTankGrid.Move(index, lastBounds, world.EnemyTanks[index].Hull().Bounds())
+	}
+}
+
+func (world *World) updatePlayerTanks(dt float32) {
+	for index := range world.Players {
+		player := &world.Players[index]
+		if player.Lost {
+			// the controllers are asked on every step, even after losing, so that the replays stay in step
+			player.Tank.Controller.Update(world, &player.Tank, dt)
+			continue
+		}
+		lastPosition := player.Tank.BoundingBox.Centre()
+		if world.updateTank(&player.Tank, dt) {
+			shot := player.Tank.Shoot(world.config.BulletSize, world.config.Rules.BulletVelocity, world.r)
+			player.Bullets = append(player.Bullets, shot...)
+			player.Stats.Shots += len(shot)
+			world.Events = append(world.Events, EVENT_SHOOT)
+		}
+		if dt > 0.0 { // Step(0) would make it NaN or infinite, the last velocity is kept
+			position := player.Tank.BoundingBox.Centre()
+			player.velocity = Point{(position.X - lastPosition.X) / dt, (position.Y - lastPosition.Y) / dt}
+		}
+	}
+}
+
+/*Moves and turns the tank by the intent of it's controller.
+Returns true, if the tank wants to shoot, and it's weapon can(see Tank.CanShoot).*/
+func (world *World) updateTank(tank *Tank, dt float32) bool {
+	intent := tank.Controller.Update(world, tank, dt)
+	lastAngle := tank.RotationAngle
+	tank.Turn(intent, dt)
+	if (tank.RotationAngle != lastAngle) && !world.validTankPosition(tank.Hull(), tank) &&
+		world.validTankPosition(NewOBB(tank.BoundingBox, lastAngle), tank) /*a tank stuck already, may turn out of it*/ {
+		tank.turnHullBack(lastAngle, intent.Turret) // the corners of the tank would turn into a wall or a tank
+	}
+	if tank.SinceShot >= 0.0 {
+		tank.SinceShot += dt
+	}
+	tank.UpdateWeapon(dt)
+	shoots := intent.Fire && tank.CanShoot()
+	if shoots {
+		tank.SinceShot = 0.0
+	}
+	lastPosition := tank.BoundingBox.Centre()
+	moveX, moveY := intent.MoveX, intent.MoveY
+	if (intent.Drive != 0.0) || (tank.Speed != 0.0) {
+		// tank controls, the speed along the heading is turned into a move(Move multiplies it by the velocity)
+		tank.Accelerate(intent.Drive, world.config.Rules.TankAcceleration, world.config.Rules.TankFriction, dt)
+		heading := DegreeToRadian(float64(tank.RotationAngle))
+		moveX += tank.Speed / tank.Velocity * float32(math.Cos(heading))
+		moveY += tank.Speed / tank.Velocity * float32(math.Sin(heading))
+	}
+	if (moveX != 0.0) || (moveY != 0.0) {
+		// sliding along the walls and tanks: if the move is blocked, trying to move only horizontally, or only vertically
+		moved := false
+		moves := [][2]float32{{moveX, moveY}, {moveX, 0.0}, {0.0, moveY}}
+		for _, move := range moves {
+			if (move[0] == 0.0) && (move[1] == 0.0) {
+				continue
+			}
+			experimentalTank := tank.Move(move[0], move[1], dt)
+			if world.validTankPosition(experimentalTank.Hull(), tank) {
+				tank.BoundingBox = experimentalTank.BoundingBox
+				moved = true
+				break
+			}
+		}
+		if !moved {
+			tank.Speed = 0.0 // crashed
+		}
+	}
+	tank.Travelled += distance(lastPosition, tank.BoundingBox.Centre())
+	return shoots
+}
+
+// like ValidPosition, but a tank does not collide with itself
+func (world *World) validTankPosition(experimentalTankHull OBB, tank *Tank) bool {
+	except := -1
+	for index := range world.Players {
+		if &world.Players[index].Tank == tank {
+			except = index
+		}
+	}
+	if !world.IsInsideArena(experimentalTankHull.Bounds()) || world.Level.BlocksHull(experimentalTankHull) {
+		return false
+	}
+	for index := range world.Players {
+		if (index != except) && !world.Players[index].Lost && experimentalTankHull.Intersects(world.Players[index].Tank.Hull()) {
+			return false
+		}
+	}
+	return !world.hitsEnemyTank(experimentalTankHull, tank)
+}
+
+// the hull overlaps a(placed) enemy tank, other than except(nil for none)
+func (world *World) hitsEnemyTank(hull OBB, except *Tank) bool {
+	world.nearby = world.enemyTankGrid.Query(hull.Bounds(), world.nearby[:0])
+	for _, index := range world.nearby {
+		if (&world.EnemyTanks[index] != except) && hull.Intersects(world.EnemyTanks[index].Hull()) {
+			return true
+		}
+	}
+	return false
+}
+
+//==============DAMAGING ENEMY TANKS(by player tank bullets)==============
+func (world *World) damageEnemyTanks() {
+	for i := 0; i < len(world.EnemyTanks); i++ {
+		destroyed := false
+		for index := range world.Players {
+			for !destroyed {
+				bullet, hit := world.playerBulletGrids[index].removeHitting(world.EnemyTanks[i].Hull()) // the bullet is used up
+				if !hit {
+					break
+				}
+				world.Players[index].Stats.Hits += 1
+				destroyed = world.EnemyTanks[i].TakeDamage(bullet.DamageOr(world.config.Rules.PlayerTankBulletDamage))
+				if destroyed {
+					world.scoreKill(index, world.enemyScore(&world.EnemyTanks[i]))
+				}
+			}
+		}
+		if destroyed {
+			world.dropPowerUp(world.EnemyTanks[i].BoundingBox)
+			world.destroyEnemyTank(i)
+			i--
+		}
+	}
+}
+
+// the enemy tank at the index explodes, the last enemy tank takes it's index
+func (world *World) destroyEnemyTank(i int) {
+	world.Explosions = append(world.Explosions, world.newExplosion(world.EnemyTanks[i].BoundingBox))
+	last := len(world.EnemyTanks) - 1
+	world.enemyTankGrid.Remove(i, world.EnemyTanks[i].Hull().Bounds())
+	if i != last {
+		world.enemyTankGrid.Relabel(last, i, world.EnemyTanks[last].Hull().Bounds())
+	}
+	world.EnemyTanks = RemoveElementFromEnemyTankSlice(world.EnemyTanks, i)
+	world.Events = append(world.Events, EVENT_EXPLOSION)
+}
+
+// bullets stop on brick and steel walls, and damage them(ricochet shells bounce off them, while they can)
+func (world *World) removeBulletsHittingWalls(bullets []Bullet) []Bullet {
+	for i := 0; i < len(bullets); i++ {
+		nosePosition := bullets[i].NosePosition()
+		if !world.Level.BlocksBullet(nosePosition) {
+			continue
+		}
+		if bullets[i].Bounces > 0 {
+			world.bounceOffWall(&bullets[i])
+			continue
+		}
+		column, row := world.Level.Cell(nosePosition)
+		if world.Level.DamageTile(column, row, bullets[i].Power) {
+			explosion := world.newExplosion(world.Level.TileRect(column, row))
+			explosion.Scale = WALL_EXPLOSION
+			world.Explosions = append(world.Explosions, explosion)
+			world.Events = append(world.Events, EVENT_WALL_DESTROYED)
+		}
+		bullets = RemoveElementFromBulletSlice(bullets, i)
+		i--
+	}
+	return bullets
+}
+
+/*Removes the bullets, which are out of the arena(ricochet shells bounce back in, while they can),
+or have flown as far as their range.*/
+func (world *World) removeBulletsOutOfArena(bullets []Bullet) []Bullet {
+	for i := 0; i < len(bullets); i++ {
+		outside := !world.IsInsideArena(bullets[i].BoundingBox)
+		if outside && (bullets[i].Bounces > 0) && world.bounceOffArena(&bullets[i]) {
+			continue
+		}
+		if outside || bullets[i].Spent() {
+			bullets = RemoveElementFromBulletSlice(bullets, i)
+			i--
+		}
+	}
+	return bullets
+}
+
+//==============DAMAGING PLAYER TANKS(by enemy tank bullets)==============
+func (world *World) damagePlayerTanks(dt float32) {
+	for index := range world.Players {
+		world.Players[index].Tank.UpdateInvulnerability(dt)
+		for !world.Players[index].Lost {
+			// the bullet is used up, even if the player tank is invulnerable
+			bullet, hit := world.enemyBulletGrid.removeHitting(world.Players[index].Tank.Hull())
+			if !hit {
+				break
+			}
+			world.damagePlayerTank(index, bullet.DamageOr(world.config.Rules.EnemyTankBulletDamage))
+		}
+	}
+}
+
+//==============DAMAGING PLAYER TANKS(by the bullets of the other players, in versus or with friendly fire)==============
+func (world *World) damagePlayerTanksByPlayers() {
+	for shooter := range world.Players {
+		for target := range world.Players {
+			for (target != shooter) && !world.Players[target].Lost {
+				bullet, hit := world.playerBulletGrids[shooter].removeHitting(world.Players[target].Tank.Hull())
+				if !hit {
+					break
+				}
+				if world.config.Mode == MODE_VERSUS { // hitting the other player in co-op(friendly fire) does not count for the accuracy
+					world.Players[shooter].Stats.Hits += 1
+				}
+				if world.damagePlayerTank(target, bullet.DamageOr(world.config.Rules.PlayerTankBulletDamage)) && (world.config.Mode == MODE_VERSUS) {
+					world.scoreKill(shooter, world.config.Rules.PlayerTankScore)
+				}
+			}
+		}
+	}
+}
+
+/*Returns true, if the player tank has lost a life, taking this damage.
+It respawns, if there are lives left.*/
+func (world *World) damagePlayerTank(index int, damage int) bool {
+	player := &world.Players[index]
+	if !player.Tank.TakeDamage(damage) {
+		return false
+	}
+	world.Explosions = append(world.Explosions, world.newExplosion(player.Tank.BoundingBox))
+	world.Events = append(world.Events, EVENT_EXPLOSION)
+	player.Streak = 0 // the kill streak ends
+	player.streakTimer = 0.0
+	if player.Tank.Lives == 0 {
+		player.Lost = true
+	} else {
+		player.Tank.Respawn(world.GetRespawnPosition(player.spawnBoundingBox, world.playerTankHulls(index)), world.config.Rules.PlayerTankInvulnerability)
+	}
+	return true
+}
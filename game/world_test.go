@@ -0,0 +1,312 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// bosses in an arena with room for hardly any tank, spawning must give up instead of trying forever
+func TestSpawningInAFullArena(t *testing.T) {
+	config := DefaultConfig()
+	config.ArenaWidth, config.ArenaHeight = 200, 200
+	config.LevelSettings.MaxNumOfEnemyTanks = 20
+	config.LevelSettings.EnemySpawnOffTime = 0.1
+	config.LevelSettings.EnemyClasses = map[string]float32{"boss": 1}
+	world := NewWorld(config, 1)
+	for step := 0; step < 120; step++ {
+		world.Step(1.0 / 60.0)
+	}
+	if len(world.EnemyTanks) >= config.LevelSettings.MaxNumOfEnemyTanks {
+		t.Errorf("%d bosses fit in a 200x200 arena", len(world.EnemyTanks))
+	}
+	if world.numOfEnemyTanksSpawned != len(world.EnemyTanks) {
+		t.Errorf("%d enemy tanks counted as spawned, %d are in the arena(none has been destroyed)", world.numOfEnemyTanksSpawned, len(world.EnemyTanks))
+	}
+	for index := range world.EnemyTanks {
+		if !world.IsInsideArena(world.EnemyTanks[index].Hull().Bounds()) {
+			t.Errorf("enemy tank %d is outside of the arena", index)
+		}
+	}
+}
+
+const TEST_TIMESTEP float32 = 1.0 / 60.0
+
+/*A level of one or more players, standing still at their spawn positions(see InputController),
+without any enemy tanks and without spawning more(see placeEnemyTank).*/
+func quietWorld(players []PlayerConfig) *World {
+	config := DefaultConfig()
+	config.Players = players
+	world := NewWorld(config, 1)
+	for index := range world.Players {
+		world.Players[index].Tank.Controller = &InputController{}
+	}
+	for len(world.EnemyTanks) > 0 {
+		last := len(world.EnemyTanks) - 1
+		world.enemyTankGrid.Remove(last, world.EnemyTanks[last].Hull().Bounds())
+		world.EnemyTanks = world.EnemyTanks[:last]
+	}
+	world.numOfEnemyTanksSpawned = config.LevelSettings.MaxNumOfEnemyTanks
+	return world
+}
+
+// an enemy tank standing still at the position(it does not shoot either)
+func placeEnemyTank(world *World, position Point, health int) {
+	tank := NewTank(world.config.EnemyTankSize, 0.0, 0.0, 0.0, health, 1, &InputController{})
+	tank.Arm(weaponOf(DEFAULT_WEAPON))
+	tank.BoundingBox.X, tank.BoundingBox.Y = position.X, position.Y
+	world.EnemyTanks = append(world.EnemyTanks, tank)
+	world.enemyTankGrid.Insert(len(world.EnemyTanks)-1, tank.Hull().Bounds())
+}
+
+// an enemy bullet, flying from the left at the centre of the player tank
+func shootAtPlayer(world *World, index int) {
+	centre := world.Players[index].Tank.BoundingBox.Centre()
+	world.EnemyTankBullets = append(world.EnemyTankBullets, Bullet{
+		Velocity:    world.config.Rules.BulletVelocity,
+		Power:       BULLET_POWER,
+		BoundingBox: Rect{X: centre.X - 100, Y: centre.Y, W: world.config.BulletSize.W, H: world.config.BulletSize.H},
+	})
+}
+
+// steps the world, until there are no bullets flying(or a second has passed)
+func stepUntilBulletsAreGone(world *World) {
+	for step := 0; step < 60; step++ {
+		world.Step(TEST_TIMESTEP)
+		if (len(world.EnemyTankBullets) == 0) && (len(world.Players[0].Bullets) == 0) {
+			return
+		}
+	}
+}
+
+func TestShootingEnemyTanks(t *testing.T) {
+	world := quietWorld([]PlayerConfig{{}})
+	player := &world.Players[0]
+	controller := player.Tank.Controller.(*InputController)
+	// the player tank faces right(angle 0), the enemy tank is in front of it
+	placeEnemyTank(world, Point{player.Tank.BoundingBox.X + 150, player.Tank.BoundingBox.Y}, 2)
+
+	controller.Input.Shoot = true
+	world.Step(TEST_TIMESTEP)
+	controller.Input.Shoot = false
+	if len(player.Bullets) != 1 {
+		t.Fatalf("%d bullets shot, expected 1", len(player.Bullets))
+	}
+	stepUntilBulletsAreGone(world)
+	if (len(world.EnemyTanks) != 1) || (world.EnemyTanks[0].Health != 1) {
+		t.Fatalf("the enemy tank should have 1 health left, after one bullet(enemy tanks: %d)", len(world.EnemyTanks))
+	}
+	if player.Stats.Hits != 1 {
+		t.Errorf("%d hits, expected 1", player.Stats.Hits)
+	}
+
+	controller.Input.Shoot = true
+	world.Step(TEST_TIMESTEP)
+	controller.Input.Shoot = false
+	stepUntilBulletsAreGone(world)
+	if len(world.EnemyTanks) != 0 {
+		t.Fatal("the enemy tank should be destroyed, after two bullets")
+	}
+	if (player.Stats.Kills != 1) || (len(world.Explosions) == 0) {
+		t.Errorf("%d kills and %d explosions, expected a kill and it's explosion", player.Stats.Kills, len(world.Explosions))
+	}
+	if player.Score < world.config.Rules.EnemyTankScore {
+		t.Errorf("score %d, expected at least %d", player.Score, world.config.Rules.EnemyTankScore)
+	}
+
+	// every enemy tank has been spawned and destroyed
+	world.Step(TEST_TIMESTEP)
+	if world.State != STATE_PLAYER_WON {
+		t.Errorf("state %d, expected the level to be won", world.State)
+	}
+}
+
+// the first player shoots the second one, standing to the right of it
+func TestHittingTheOtherPlayer(t *testing.T) {
+	tests := []struct {
+		name         string
+		mode         Mode
+		friendlyFire bool
+		damage       bool
+		hits         int
+	}{
+		{"co-op", MODE_COOP, false, false, 0},
+		{"co-op with friendly fire", MODE_COOP, true, true, 0}, // hitting the partner does not make the accuracy better
+		{"versus", MODE_VERSUS, false, true, 1},
+	}
+	for _, test := range tests {
+		world := quietWorld([]PlayerConfig{{}, {}})
+		world.config.Mode, world.config.FriendlyFire = test.mode, test.friendlyFire
+		placeEnemyTank(world, Point{10, 10}, 1) // so that the level is not won
+		shooter, target := &world.Players[0], &world.Players[1]
+		controller := shooter.Tank.Controller.(*InputController)
+		controller.Input.Shoot = true
+		world.Step(TEST_TIMESTEP)
+		controller.Input.Shoot = false
+		stepUntilBulletsAreGone(world)
+
+		maxHealth := world.config.Rules.PlayerTankMaxHealth
+		if damaged := target.Tank.Health < maxHealth; damaged != test.damage {
+			t.Errorf("%s: health of the other player %d, expected it to be damaged: %v", test.name, target.Tank.Health, test.damage)
+		}
+		if (shooter.Stats.Shots != 1) || (shooter.Stats.Hits != test.hits) {
+			t.Errorf("%s: %d shots and %d hits, expected 1 shot and %d hits", test.name, shooter.Stats.Shots, shooter.Stats.Hits, test.hits)
+		}
+	}
+}
+
+func TestLivesAndRespawning(t *testing.T) {
+	world := quietWorld([]PlayerConfig{{Lives: 2}})
+	placeEnemyTank(world, Point{10, 10}, 1) // so that the level is not won
+	player := &world.Players[0]
+	spawn := player.Tank.BoundingBox
+	player.Tank.BoundingBox.X += 100 // it has driven away from the spawn position
+
+	maxHealth := world.config.Rules.PlayerTankMaxHealth
+	for hit := 1; hit < maxHealth; hit++ {
+		shootAtPlayer(world, 0)
+		stepUntilBulletsAreGone(world)
+		if player.Tank.Health != maxHealth-hit {
+			t.Fatalf("health %d after %d hits, expected %d", player.Tank.Health, hit, maxHealth-hit)
+		}
+	}
+	shootAtPlayer(world, 0)
+	stepUntilBulletsAreGone(world)
+	if (player.Tank.Lives != 1) || (player.Tank.Health != maxHealth) {
+		t.Fatalf("lives %d and health %d, expected a life lost and full health", player.Tank.Lives, player.Tank.Health)
+	}
+	if player.Tank.BoundingBox != spawn {
+		t.Errorf("respawned at %v, expected the spawn position %v", player.Tank.BoundingBox, spawn)
+	}
+
+	// invulnerable for a while after respawning
+	shootAtPlayer(world, 0)
+	stepUntilBulletsAreGone(world)
+	if player.Tank.Health != maxHealth {
+		t.Errorf("health %d right after respawning, expected no damage", player.Tank.Health)
+	}
+	for step := 0; float32(step)*TEST_TIMESTEP < world.config.Rules.PlayerTankInvulnerability; step++ {
+		world.Step(TEST_TIMESTEP)
+	}
+	shootAtPlayer(world, 0)
+	stepUntilBulletsAreGone(world)
+	if player.Tank.Health != maxHealth-1 {
+		t.Errorf("health %d after the invulnerability, expected %d", player.Tank.Health, maxHealth-1)
+	}
+
+	// the last life
+	for !player.Lost {
+		shootAtPlayer(world, 0)
+		stepUntilBulletsAreGone(world)
+		if world.Ticks > 10000 {
+			t.Fatal("the player tank does not lose it's last life")
+		}
+	}
+	for step := 0; (step < 600) && (world.State == STATE_RUNNING); step++ {
+		world.Step(TEST_TIMESTEP) // the explosion of the player tank
+	}
+	if world.State != STATE_PLAYER_LOST {
+		t.Errorf("state %d, expected game over", world.State)
+	}
+}
+
+// the state of the world, which has to be the same for the same seed and inputs
+func snapshot(world *World) string {
+	state := fmt.Sprint(world.State, world.Ticks, len(world.EnemyTankBullets), len(world.PowerUps), len(world.Explosions))
+	for _, player := range world.Players {
+		state += fmt.Sprint(player.Tank.BoundingBox, player.Tank.RotationAngle, player.Tank.Health, player.Tank.Lives, player.Score, len(player.Bullets))
+	}
+	for _, tank := range world.EnemyTanks {
+		state += fmt.Sprint(tank.BoundingBox, tank.RotationAngle, tank.Health)
+	}
+	for _, bullet := range world.EnemyTankBullets {
+		state += fmt.Sprint(bullet.BoundingBox)
+	}
+	return state
+}
+
+// random keys, held for a random number of steps
+func randomInputs(steps int, players int, seed int64) [][]Input {
+	r := rand.New(rand.NewSource(seed))
+	inputs := make([][]Input, steps)
+	current := make([]Input, players)
+	for step := range inputs {
+		for index := range current {
+			if r.Intn(10) == 0 {
+				current[index] = Input{
+					RotateAntiClockWise: r.Intn(4) == 0,
+					RotateClockWise:     r.Intn(4) == 0,
+					MoveUp:              r.Intn(3) == 0,
+					MoveLeft:            r.Intn(3) == 0,
+					MoveDown:            r.Intn(3) == 0,
+					MoveRight:           r.Intn(3) == 0,
+				}
+				if r.Intn(4) == 0 { // a gamepad
+					current[index].MoveX = int8(r.Intn(255) - 127)
+					current[index].Aim = true
+					current[index].AimAngle = uint8(r.Intn(256))
+				}
+			}
+			current[index].Shoot = r.Intn(8) == 0
+		}
+		inputs[step] = append([]Input(nil), current...)
+	}
+	return inputs
+}
+
+// a campaign level with the AI, played by these inputs(the world is stepped once for every input)
+func playWorld(seed int64, inputs [][]Input) []string {
+	config := DefaultConfig()
+	controllers := make([]*InputController, len(inputs[0]))
+	for index := range controllers {
+		controllers[index] = &InputController{}
+		config.Players = append(config.Players, PlayerConfig{Controller: controllers[index]})
+	}
+	world := NewWorld(config, seed)
+	var states []string
+	for _, step := range inputs {
+		for index := range controllers {
+			controllers[index].Input = step[index]
+		}
+		world.Step(TEST_TIMESTEP)
+		states = append(states, snapshot(world))
+	}
+	return states
+}
+
+func TestDeterminism(t *testing.T) {
+	for _, players := range []int{1, 2} {
+		inputs := randomInputs(1200, players, 7)
+		first, second := playWorld(42, inputs), playWorld(42, inputs)
+		for step := range first {
+			if first[step] != second[step] {
+				t.Fatalf("%d players: the worlds went apart on step %d:\n%s\n%s", players, step, first[step], second[step])
+			}
+		}
+		if other := playWorld(43, inputs); other[len(other)-1] == first[len(first)-1] {
+			t.Errorf("%d players: another seed gave the same game", players)
+		}
+	}
+}
+
+// Step is public, a step of no time must not break the velocity of the player tank(the enemy tanks aim by it)
+func TestStepWithoutTime(t *testing.T) {
+	world := quietWorld([]PlayerConfig{{}})
+	placeEnemyTank(world, Point{10, 10}, 1) // so that the level is not won
+	player := &world.Players[0]
+	player.Tank.Controller.(*InputController).Input.MoveRight = true
+	world.Step(TEST_TIMESTEP)
+	velocity := player.velocity
+	if velocity.X <= 0.0 {
+		t.Fatalf("velocity %v, expected the player tank to move right", velocity)
+	}
+	for step := 0; step < 3; step++ {
+		world.Step(0.0)
+		if !IsFinite(player.velocity.X) || !IsFinite(player.velocity.Y) {
+			t.Fatalf("velocity %v after Step(0)", player.velocity)
+		}
+	}
+	if player.velocity != velocity {
+		t.Errorf("velocity %v after Step(0), expected the last one %v", player.velocity, velocity)
+	}
+}
@@ -0,0 +1,207 @@
+// gamepad.go
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/dev-abir/tanks/game"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+/*
+
+Gamepads(SDL game controllers), they can be plugged in and out at any time.
+A new gamepad goes to the first player(in the order given by --gamepads) without one,
+and the gamepad of a player works together with the keys of that player:
+
+	left stick, d-pad            move
+	right stick                  aim(the tank turns towards the stick)
+	left/right shoulder          rotate anti clock wise/clock wise
+	right trigger, A             shoot
+
+With tank controls(see game.Rules.TankControls), the left stick drives(up and down) and steers(left and right),
+and the right stick and the shoulders turn the turret.
+
+*/
+
+const (
+	GAMEPAD_DEADZONE          float32 = 0.25 // of the full range of a stick, smaller values are ignored
+	GAMEPAD_TRIGGER_THRESHOLD int16   = 16000
+)
+
+// assigns gamepads to players
+type Gamepads struct {
+	order       []int                 // indices of the players, who get the gamepads, in the order the gamepads are connected
+	controllers []*sdl.GameController // of every player, nil if that player has no gamepad
+	shooting    []bool                // the shoot button was held in the last frame(a held button shoots only once)
+}
+
+// parses --gamepads, a comma separated list of players(1, 2...), empty means every player in order
+func ParseGamepadOrder(value string, players int) ([]int, error) {
+	var order []int
+	if strings.TrimSpace(value) == "" {
+		for index := 0; index < players; index++ {
+			order = append(order, index)
+		}
+		return order, nil
+	}
+	for _, field := range strings.Split(value, ",") {
+		player, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a player number", field)
+		}
+		if (player < 1) || (player > players) {
+			return nil, fmt.Errorf("there is no player %d(%d players are playing)", player, players)
+		}
+		for _, other := range order {
+			if other == player-1 {
+				return nil, fmt.Errorf("player %d is given more than once", player)
+			}
+		}
+		order = append(order, player-1)
+	}
+	return order, nil
+}
+
+func NewGamepads(players int, order []int) *Gamepads {
+	gamepads := &Gamepads{
+		order:       order,
+		controllers: make([]*sdl.GameController, players),
+		shooting:    make([]bool, players),
+	}
+	if err := sdl.InitSubSystem(sdl.INIT_GAMECONTROLLER); err != nil {
+		HandleError("Cannot use gamepads, you may play with the keyboard: ", err)
+		return gamepads
+	}
+	gamepads.Scan()
+	return gamepads
+}
+
+func (gamepads *Gamepads) Close() {
+	for index, controller := range gamepads.controllers {
+		if controller != nil {
+			controller.Close()
+			gamepads.controllers[index] = nil
+		}
+	}
+}
+
+// opens the connected gamepads, which are not opened yet(the connection events may have been missed by a screen)
+func (gamepads *Gamepads) Scan() {
+	for index, controller := range gamepads.controllers {
+		if (controller != nil) && !controller.Attached() {
+			gamepads.remove(index)
+		}
+	}
+	for device := 0; device < sdl.NumJoysticks(); device++ {
+		gamepads.add(device)
+	}
+}
+
+// handles the connection events of the gamepads, returns false if the event is not about them
+func (gamepads *Gamepads) HandleEvent(event sdl.Event) bool {
+	t, ok := event.(*sdl.ControllerDeviceEvent)
+	if !ok {
+		return false
+	}
+	switch t.Type {
+	case sdl.CONTROLLERDEVICEADDED: // Which is the device index
+		gamepads.add(int(t.Which))
+	case sdl.CONTROLLERDEVICEREMOVED: // Which is the instance id
+		for index, controller := range gamepads.controllers {
+			if (controller != nil) && (controller.Joystick().InstanceID() == t.Which) {
+				gamepads.remove(index)
+			}
+		}
+	}
+	return true
+}
+
+func (gamepads *Gamepads) add(device int) {
+	if !sdl.IsGameController(device) {
+		return
+	}
+	id := sdl.JoystickGetDeviceInstanceID(device)
+	for _, controller := range gamepads.controllers {
+		if (controller != nil) && (controller.Joystick().InstanceID() == id) {
+			return // already opened
+		}
+	}
+	for _, player := range gamepads.order {
+		if gamepads.controllers[player] != nil {
+			continue
+		}
+		controller := sdl.GameControllerOpen(device)
+		if controller == nil {
+			HandleError("Failed to open gamepad: ", sdl.GetError())
+			return
+		}
+		gamepads.controllers[player] = controller
+		gamepads.shooting[player] = true // a button held while connecting should not shoot
+		fmt.Printf("Gamepad %q connected, for player %d\n", controller.Name(), player+1)
+		return
+	}
+}
+
+func (gamepads *Gamepads) remove(player int) {
+	fmt.Printf("Gamepad %q disconnected, player %d\n", gamepads.controllers[player].Name(), player+1)
+	gamepads.controllers[player].Close()
+	gamepads.controllers[player] = nil
+}
+
+// a stick, -1 to 1 on both the axes, zero inside the deadzone
+func stick(controller *sdl.GameController, axisX sdl.GameControllerAxis, axisY sdl.GameControllerAxis) (float32, float32) {
+	x := float32(controller.Axis(axisX)) / 32767.0
+	y := float32(controller.Axis(axisY)) / 32767.0
+	length := float32(math.Hypot(float64(x), float64(y)))
+	if length < GAMEPAD_DEADZONE {
+		return 0.0, 0.0
+	}
+	// rescaling, so that the movement starts slowly just outside the deadzone
+	scale := (float32(math.Min(float64(length), 1.0)) - GAMEPAD_DEADZONE) / (1.0 - GAMEPAD_DEADZONE) / length
+	return x * scale, y * scale
+}
+
+/*Adds the gamepad of the player to the input of the keyboard.
+Call it once in every frame, shooting is only on pressing the trigger(or A), not while holding it.*/
+func (gamepads *Gamepads) Input(player int, input game.Input) game.Input {
+	controller := gamepads.controllers[player]
+	if controller == nil {
+		return input
+	}
+	button := func(b sdl.GameControllerButton) bool {
+		return controller.Button(b) == 1
+	}
+
+	moveX, moveY := stick(controller, sdl.CONTROLLER_AXIS_LEFTX, sdl.CONTROLLER_AXIS_LEFTY)
+	input.MoveX = game.QuantizeAxis(moveX)
+	input.MoveY = game.QuantizeAxis(moveY)
+	input.MoveUp = input.MoveUp || button(sdl.CONTROLLER_BUTTON_DPAD_UP)
+	input.MoveLeft = input.MoveLeft || button(sdl.CONTROLLER_BUTTON_DPAD_LEFT)
+	input.MoveDown = input.MoveDown || button(sdl.CONTROLLER_BUTTON_DPAD_DOWN)
+	input.MoveRight = input.MoveRight || button(sdl.CONTROLLER_BUTTON_DPAD_RIGHT)
+	input.RotateAntiClockWise = input.RotateAntiClockWise || button(sdl.CONTROLLER_BUTTON_LEFTSHOULDER)
+	input.RotateClockWise = input.RotateClockWise || button(sdl.CONTROLLER_BUTTON_RIGHTSHOULDER)
+
+	aimX, aimY := stick(controller, sdl.CONTROLLER_AXIS_RIGHTX, sdl.CONTROLLER_AXIS_RIGHTY)
+	if (aimX != 0.0) || (aimY != 0.0) {
+		input.Aim = true
+		// the same angles as the tanks, 0 is right and the angle grows clock wise(y grows downwards)
+		input.AimAngle = game.QuantizeAngle(float32(math.Atan2(float64(aimY), float64(aimX)) * 180.0 / math.Pi))
+	}
+
+	shooting := (controller.Axis(sdl.CONTROLLER_AXIS_TRIGGERRIGHT) > GAMEPAD_TRIGGER_THRESHOLD) || button(sdl.CONTROLLER_BUTTON_A)
+	if shooting && !gamepads.shooting[player] {
+		input.Shoot = true
+	}
+	gamepads.shooting[player] = shooting
+	return input
+}
+
+// the player holds the trigger(or A) of the gamepad, automatic weapons keep shooting(see game.Weapon)
+func (gamepads *Gamepads) Shooting(player int) bool {
+	return (gamepads.controllers[player] != nil) && gamepads.shooting[player]
+}
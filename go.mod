@@ -1,6 +1,6 @@
 module github.com/dev-abir/tanks
 
-go 1.14
+go 1.16
 
 require (
 	github.com/faiface/pixel v0.9.0
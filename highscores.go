@@ -0,0 +1,184 @@
+// highscores.go
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dev-abir/tanks/game"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+/*
+
+The best scores of the campaign are kept in a JSON file in the data directory of the user(see HighScoresPath),
+for example:
+
+	[
+		{"initials": "ABI", "score": 12400, "level": 5, "date": "2021-06-01"},
+		...
+	]
+
+Only the best HIGH_SCORES_KEPT scores are kept, the best first. Versus games, replays and single maps(--map)
+are not counted.
+
+*/
+
+const (
+	HIGH_SCORES_KEPT    int    = 10
+	INITIALS_LENGTH     int    = 3
+	INITIALS_CHARACTERS string = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789" // the font has no lower case letters
+	HIGH_SCORE_DATE     string = "2006-01-02"                           // layout of the dates(see time.Format)
+)
+
+type HighScore struct {
+	Initials string `json:"initials"`
+	Score    int    `json:"score"`
+	Level    int    `json:"level"` // the level reached, from 1
+	Date     string `json:"date"`  // HIGH_SCORE_DATE
+}
+
+type HighScores struct {
+	Entries []HighScore // the best first
+
+	path string // where the high scores are saved, empty if they are not saved
+}
+
+/*The high scores file in the data directory of the user(~/.local/share/tanks/highscores.json on linux,
+the config directory of the user on windows and mac, which is where the data goes there too).*/
+func HighScoresPath() (string, error) {
+	dir := ""
+	switch runtime.GOOS {
+	case "windows", "darwin", "ios", "plan9":
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		dir = configDir
+	default:
+		dir = os.Getenv("XDG_DATA_HOME")
+		if dir == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			dir = filepath.Join(home, ".local", "share")
+		}
+	}
+	return filepath.Join(dir, USER_DIRECTORY, "highscores.json"), nil
+}
+
+// loads the high scores from the file, if there is no file, the table is empty(and saved there, when a score gets in)
+func LoadHighScores(path string) (*HighScores, error) {
+	scores := &HighScores{path: path}
+	if path == "" {
+		return scores, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return scores, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &scores.Entries); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(scores.Entries, func(i, j int) bool { return scores.Entries[i].Score > scores.Entries[j].Score })
+	if len(scores.Entries) > HIGH_SCORES_KEPT {
+		scores.Entries = scores.Entries[:HIGH_SCORES_KEPT]
+	}
+	return scores, nil
+}
+
+// returns true, if this score would get into the table
+func (scores *HighScores) Qualifies(score int) bool {
+	if score <= 0 {
+		return false
+	}
+	return (len(scores.Entries) < HIGH_SCORES_KEPT) || (score > scores.Entries[len(scores.Entries)-1].Score)
+}
+
+/*Puts the score into the table(after the equal scores, the older score stays in front), dated today.
+Returns the index of the new entry, -1 if it did not get in.*/
+func (scores *HighScores) Insert(initials string, score int, level int) int {
+	if !scores.Qualifies(score) {
+		return -1
+	}
+	index := sort.Search(len(scores.Entries), func(i int) bool { return scores.Entries[i].Score < score })
+	entry := HighScore{
+		Initials: strings.ToUpper(initials),
+		Score:    score,
+		Level:    level,
+		Date:     time.Now().Format(HIGH_SCORE_DATE),
+	}
+	scores.Entries = append(scores.Entries, HighScore{})
+	copy(scores.Entries[index+1:], scores.Entries[index:])
+	scores.Entries[index] = entry
+	if len(scores.Entries) > HIGH_SCORES_KEPT {
+		scores.Entries = scores.Entries[:HIGH_SCORES_KEPT]
+	}
+	return index
+}
+
+func (scores *HighScores) Save() error {
+	if scores.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(scores.Entries, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(scores.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(scores.path, data, 0644)
+}
+
+/*The results of the game, then the initials of every player with a new high score, and the high scores,
+with the new entries highlighted. level is the level reached.*/
+func ShowResults(renderer *sdl.Renderer, seconds float32, scores *HighScores, players []game.PlayerConfig, stats []game.Stats, level int) {
+	if !ShowScreen(renderer, seconds, true, ResultLines(players, stats)...) {
+		return
+	}
+	var highlighted []int
+	added := false
+	for index := range players {
+		if !scores.Qualifies(players[index].Score) {
+			continue
+		}
+		title := "NEW HIGH SCORE!"
+		if len(players) > 1 {
+			title = fmt.Sprintf("P%d NEW HIGH SCORE!", index+1)
+		}
+		initials, ok := InitialsScreen(renderer, title, players[index].Score)
+		if !ok {
+			break
+		}
+		entry := scores.Insert(initials, players[index].Score, level)
+		// the entries below the new one move down(or out of the table)
+		var moved []int
+		for _, other := range highlighted {
+			if other >= entry {
+				other += 1
+			}
+			if other < HIGH_SCORES_KEPT {
+				moved = append(moved, other)
+			}
+		}
+		highlighted = append(moved, entry)
+		added = true
+	}
+	if added {
+		if err := scores.Save(); err != nil {
+			HandleError("Failed to save high scores "+scores.path+": ", err)
+		}
+	}
+	HighScoresScreen(renderer, scores, seconds, highlighted...)
+}
@@ -2,20 +2,21 @@
 package main
 
 import (
+	"crypto/sha256"
+	"errors"
+	"flag"
 	"fmt"
-	"math/rand"
 	"os"
 	"time"
 
-	"golang.org/x/image/colornames"
-
+	"github.com/dev-abir/tanks/game"
 	"github.com/veandco/go-sdl2/mix"
 	"github.com/veandco/go-sdl2/sdl"
 )
 
 //==============SETTINGS==============
 const (
-	//==============WINDOW SETTINGS==============
+	//==============WINDOW SETTINGS(the defaults, see config.go)==============
 	TITLE           string = "Tank game"
 	SCREEN_WIDTH    int32  = 500
 	SCREEN_HEIGHT   int32  = 500
@@ -23,6 +24,12 @@ const (
 	SMOOTH_TEXTURES bool   = true
 )
 
+const (
+	//==============SIMULATION SETTINGS==============
+	FIXED_TIMESTEP      float32 = 1.0 / 60.0 // seconds, the world is always stepped with this dt
+	MAX_STEPS_PER_FRAME int     = 5          // if rendering is too slow, the game slows down, instead of freezing(spiral of death)
+)
+
 const (
 	//==============ANTI-ALIASING TYPES==============
 	NEAREST     string = "0"
@@ -32,66 +39,198 @@ const (
 
 const (
 	//==============TEXTURE PATHS==============
-	PLAYER_TANK_TEXTURE_PATH string = "resources/player-tank.png" // Make the textures, to be in same rotation angle
-	ENEMY_TANK_TEXTURE_PATH  string = "resources/enemy-tank.png"  // Make the textures, to be in same rotation angle
-	BULLET_TEXTURE_PATH      string = "resources/bullet_6.png"    // Make the textures, to be in same rotation angle
+	PLAYER_TANK_TEXTURE_PATH      string = "resources/player-tank.png"      // Make the textures, to be in same rotation angle
+	PLAYER_TANK_HULL_TEXTURE_PATH string = "resources/player-tank-hull.png" // the player tank without it's turret(with tank controls)
+	TURRET_TEXTURE_PATH           string = "resources/turret.png"           // as big as the tank, turned around it's centre
+	CROSSHAIR_TEXTURE_PATH        string = "resources/crosshair.png"        // drawn at the mouse, while it aims(see mouse.go)
+	ENEMY_TANK_TEXTURE_PATH       string = "resources/enemy-tank.png"       // Make the textures, to be in same rotation angle
+	BULLET_TEXTURE_PATH           string = "resources/bullet_6.png"         // Make the textures, to be in same rotation angle
+	TILES_TEXTURE_PATH            string = "resources/tiles.png"            // brick, steel, water and trees, from left to right(in the order of game.Tile), damaged tiles in the second row
 
 	//==============SOUND EFFECTS PATHS==============
 	SHOOT_SOUND_PATH     string = "resources/flak_gun_sound.ogg"
 	EXPLOSION_SOUND_PATH string = "resources/bombexplosion.ogg"
 )
 
-//==============EXPLOSION ANIMATION==============
+//==============LEVELS==============
 const (
-	EXPLOSION_ANIMATION_TEXTURE_PATH string = "resources/explosion_animation.png"
-
-	// for now, these are hard coded(change these, if you change the texture image)
-	CELL_WIDTH                   int32 = 128
-	CELL_HEIGHT                  int32 = 128
-	NUMBER_OF_CELLS_LENGTH_WISE  int   = 8
-	NUMBER_OF_CELLS_BREADTH_WISE int   = 8
+	CAMPAIGN_PATH         string  = "resources/campaign.json"
+	TILE_CELL_SIZE        int32   = 32  // size of one tile in the tiles texture(of the default pack)
+	INTERMISSION_TIME     float32 = 3.0 // seconds, the intermission screen is shown before every level(or until 'space' is pressed)
+	GAME_OVER_SCREEN_TIME float32 = 2.0 // seconds
+	RESULTS_SCREEN_TIME   float32 = 8.0 // seconds, the results and the high scores are shown after the game(or until 'space' is pressed)
 )
 
-var EXPLOSION_ANIMATION_COORDS [NUMBER_OF_CELLS_LENGTH_WISE * NUMBER_OF_CELLS_BREADTH_WISE]sdl.Point = [NUMBER_OF_CELLS_LENGTH_WISE * NUMBER_OF_CELLS_BREADTH_WISE]sdl.Point{
-	sdl.Point{CELL_WIDTH * 0, CELL_HEIGHT * 0}, sdl.Point{CELL_WIDTH * 1, CELL_HEIGHT * 0}, sdl.Point{CELL_WIDTH * 2, CELL_HEIGHT * 0}, sdl.Point{CELL_WIDTH * 3, CELL_HEIGHT * 0}, sdl.Point{CELL_WIDTH * 4, CELL_HEIGHT * 0}, sdl.Point{CELL_WIDTH * 5, CELL_HEIGHT * 0}, sdl.Point{CELL_WIDTH * 6, CELL_HEIGHT * 0}, sdl.Point{CELL_WIDTH * 7, CELL_HEIGHT * 0},
-	sdl.Point{CELL_WIDTH * 0, CELL_HEIGHT * 1}, sdl.Point{CELL_WIDTH * 1, CELL_HEIGHT * 1}, sdl.Point{CELL_WIDTH * 2, CELL_HEIGHT * 1}, sdl.Point{CELL_WIDTH * 3, CELL_HEIGHT * 1}, sdl.Point{CELL_WIDTH * 4, CELL_HEIGHT * 1}, sdl.Point{CELL_WIDTH * 5, CELL_HEIGHT * 1}, sdl.Point{CELL_WIDTH * 6, CELL_HEIGHT * 1}, sdl.Point{CELL_WIDTH * 7, CELL_HEIGHT * 1},
-	sdl.Point{CELL_WIDTH * 0, CELL_HEIGHT * 2}, sdl.Point{CELL_WIDTH * 1, CELL_HEIGHT * 2}, sdl.Point{CELL_WIDTH * 2, CELL_HEIGHT * 2}, sdl.Point{CELL_WIDTH * 3, CELL_HEIGHT * 2}, sdl.Point{CELL_WIDTH * 4, CELL_HEIGHT * 2}, sdl.Point{CELL_WIDTH * 5, CELL_HEIGHT * 2}, sdl.Point{CELL_WIDTH * 6, CELL_HEIGHT * 2}, sdl.Point{CELL_WIDTH * 7, CELL_HEIGHT * 2},
-	sdl.Point{CELL_WIDTH * 0, CELL_HEIGHT * 3}, sdl.Point{CELL_WIDTH * 1, CELL_HEIGHT * 3}, sdl.Point{CELL_WIDTH * 2, CELL_HEIGHT * 3}, sdl.Point{CELL_WIDTH * 3, CELL_HEIGHT * 3}, sdl.Point{CELL_WIDTH * 4, CELL_HEIGHT * 3}, sdl.Point{CELL_WIDTH * 5, CELL_HEIGHT * 3}, sdl.Point{CELL_WIDTH * 6, CELL_HEIGHT * 3}, sdl.Point{CELL_WIDTH * 7, CELL_HEIGHT * 3},
-	sdl.Point{CELL_WIDTH * 0, CELL_HEIGHT * 4}, sdl.Point{CELL_WIDTH * 1, CELL_HEIGHT * 4}, sdl.Point{CELL_WIDTH * 2, CELL_HEIGHT * 4}, sdl.Point{CELL_WIDTH * 3, CELL_HEIGHT * 4}, sdl.Point{CELL_WIDTH * 4, CELL_HEIGHT * 4}, sdl.Point{CELL_WIDTH * 5, CELL_HEIGHT * 4}, sdl.Point{CELL_WIDTH * 6, CELL_HEIGHT * 4}, sdl.Point{CELL_WIDTH * 7, CELL_HEIGHT * 4},
-	sdl.Point{CELL_WIDTH * 0, CELL_HEIGHT * 5}, sdl.Point{CELL_WIDTH * 1, CELL_HEIGHT * 5}, sdl.Point{CELL_WIDTH * 2, CELL_HEIGHT * 5}, sdl.Point{CELL_WIDTH * 3, CELL_HEIGHT * 5}, sdl.Point{CELL_WIDTH * 4, CELL_HEIGHT * 5}, sdl.Point{CELL_WIDTH * 5, CELL_HEIGHT * 5}, sdl.Point{CELL_WIDTH * 6, CELL_HEIGHT * 5}, sdl.Point{CELL_WIDTH * 7, CELL_HEIGHT * 5},
-	sdl.Point{CELL_WIDTH * 0, CELL_HEIGHT * 6}, sdl.Point{CELL_WIDTH * 1, CELL_HEIGHT * 6}, sdl.Point{CELL_WIDTH * 2, CELL_HEIGHT * 6}, sdl.Point{CELL_WIDTH * 3, CELL_HEIGHT * 6}, sdl.Point{CELL_WIDTH * 4, CELL_HEIGHT * 6}, sdl.Point{CELL_WIDTH * 5, CELL_HEIGHT * 6}, sdl.Point{CELL_WIDTH * 6, CELL_HEIGHT * 6}, sdl.Point{CELL_WIDTH * 7, CELL_HEIGHT * 6},
-	sdl.Point{CELL_WIDTH * 0, CELL_HEIGHT * 7}, sdl.Point{CELL_WIDTH * 1, CELL_HEIGHT * 7}, sdl.Point{CELL_WIDTH * 2, CELL_HEIGHT * 7}, sdl.Point{CELL_WIDTH * 3, CELL_HEIGHT * 7}, sdl.Point{CELL_WIDTH * 4, CELL_HEIGHT * 7}, sdl.Point{CELL_WIDTH * 5, CELL_HEIGHT * 7}, sdl.Point{CELL_WIDTH * 6, CELL_HEIGHT * 7}, sdl.Point{CELL_WIDTH * 7, CELL_HEIGHT * 7},
-}
-
-///////////////////////////////////////////////////////////////////////////////////////////////////////////////
+//==============ANIMATIONS==============
+// sprite sheets, the frames are described by the sidecar files(resources/explosion_animation.json..., see sprites.go)
 const (
-	//==============GAMEPLAY==============
-	BULLET_VELOCITY               float32 = 500
-	TANK_ROTATION_ANGLE           float32 = 500 // TODO : Why so low rotation on setting this to 5?(maybe due to delta calculation)
-	PLAYER_TANK_VELOCITY          float32 = 300
-	EXPLOSION_ANIMATION_LIFE_SPAN float32 = 0.5 // seconds
-
-	//==============SPECIAL FLAGS==============
-	CRAZY_TANKS bool = false // A special flag, toggle it, and enjoy!
+	EXPLOSION_ANIMATION_TEXTURE_PATH string = "resources/explosion_animation.png"
+	MUZZLE_FLASH_TEXTURE_PATH        string = "resources/muzzle_flash.png"
+	TREADS_TEXTURE_PATH              string = "resources/treads.png" // drawn over the tanks, with the size of the tanks
+	SPARKLE_TEXTURE_PATH             string = "resources/sparkle.png"
+
+	// the animations needed in the sheets
+	ANIMATION_EXPLOSION    string = "explosion" // stretched over the life span of the explosion(the durations are relative)
+	ANIMATION_MUZZLE_FLASH string = "muzzleFlash"
+	ANIMATION_TREADS       string = "treads" // runs by the distance travelled, not by the time(see TREADS_PIXELS_PER_SECOND)
+	ANIMATION_SPARKLE      string = "sparkle"
+
+	EXPLOSION_SIZE           float32 = 128 // pixels on the screen, of an explosion with scale 1(the frames of any pack are scaled to this)
+	MUZZLE_FLASH_SIZE        float32 = 16  // pixels, width
+	TREADS_PIXELS_PER_SECOND float32 = 100 // a tank moving this many pixels runs one second of the treads animation
 )
 
-//==============LEVEL SETTINGS==============
-const (
-	LEVEL_0_MAX_NUM_OF_ENEMY_TANKS         int     = 10
-	LEVEL_0_ENEMY_SPAWN_OFF_TIME           float32 = 3.0 // seconds
-	LEVEL_0_ENEMY_TANK_VELOCITY            float32 = 310
-	LEVEL_0_ENEMY_TANK_MIN_NO_UPDATES_TIME float32 = 1.0 // seconds
-	LEVEL_0_ENEMY_TANK_MAX_NO_UPDATES_TIME float32 = 3.0 // seconds
-)
+func run() int {
 
-/*
-TODO : Use batch rendering, it is necessary for specifically a large number of bullets
-*/
+	//==============COMMAND LINE==============
+	seed := flag.Int64("seed", 0, "seed of the random number generator, the same seed(with the same inputs) gives the same game(0 means a random seed)")
+	recordPath := flag.String("record", "", "record the game into this replay file")
+	replayPath := flag.String("replay", "", "play this replay file, instead of reading the keyboard")
+	startLevel := flag.Int("level", 1, "start the campaign from this level")
+	levelPath := flag.String("map", "", "play only this level file(see resources/levels), with the settings of the level selected by --level")
+	configPath := flag.String("config", "", "config file(see config.go), default is config.json in the config directory of the user")
+	printConfig := flag.Bool("print-config", false, "print the settings(the defaults, with the config file, the environment variables and the flags), and exit")
+	benchBullets := flag.Int("bench-bullets", 0, "draw the level with this many bullets, batched and one by one, print the FPS of both, and exit(run with --vsync=false)")
+	benchTanks := flag.Int("bench-tanks", 0, "step a world with this many enemy tanks(and --bench-bullets bullets) without a window, print how long the steps took, and exit")
+	settingFlags := RegisterSettingFlags(flag.CommandLine)
+	flag.Parse()
+	if *seed == 0 {
+		*seed = time.Now().UnixNano() // TODO : What to do after 2262(UnixNano)???
+	}
 
-func run() int {
+	//==============SETTINGS==============
+	required := *configPath != "" // the default config file is optional
+	if !required {
+		path, err := SettingsPath()
+		if err != nil {
+			HandleError("Cannot find the config directory, the default settings will be used: ", err)
+		}
+		*configPath = path
+	}
+	settings, err := LoadSettings(*configPath, required, settingFlags)
+	if err != nil {
+		HandleError("Invalid settings:", err)
+		return ERROR_INVALID_CONFIG
+	}
+	if *printConfig {
+		fmt.Println(settings)
+		return 0
+	}
+	if *benchTanks > 0 {
+		bullets := *benchBullets
+		if bullets == 0 {
+			bullets = BENCH_DEFAULT_BULLETS
+		}
+		BenchmarkSimulation(*benchTanks, bullets, settings.Timestep, *seed)
+		return 0
+	}
+	difficulty, _ := game.FindDifficulty(settings.Difficulty) // already validated
+	mode, _ := game.FindMode(settings.Mode)
+
+	//==============REPLAY==============
+	var replay *game.Replay
+	if *replayPath != "" {
+		loadedReplay, err := ReadReplay(*replayPath)
+		if err != nil {
+			HandleError("Failed to read replay "+*replayPath+": ", err)
+			return ERROR_FAILED_TO_READ_REPLAY
+		}
+		replay = loadedReplay
+		*seed = replay.Seed
+		settings.Timestep = replay.Timestep
+		*startLevel = replay.StartLevel + 1
+		difficulty = replay.Difficulty
+		settings.Players = replay.Players
+		mode = replay.Mode
+		settings.FriendlyFire = replay.FriendlyFire
+		settings.Rules = replay.Rules
+	}
+	fmt.Println("Seed: ", *seed)
+	if (settings.Players < 1) || (settings.Players > MAX_PLAYERS) {
+		HandleError("Invalid number of players:", fmt.Errorf("%d(1 to %d players can play)", settings.Players, MAX_PLAYERS))
+		return ERROR_INVALID_GAME_MODE
+	}
+	if (mode == game.MODE_VERSUS) && (settings.Players < 2) {
+		HandleError("Invalid game mode:", errors.New("versus needs at least 2 players(see --players)"))
+		return ERROR_INVALID_GAME_MODE
+	}
+	gamepadPlayers, err := ParseGamepadOrder(settings.Gamepads, settings.Players)
+	if err != nil {
+		HandleError("Invalid gamepads: ", err)
+		return ERROR_INVALID_GAME_MODE
+	}
 
-	//==============VARS==============
-	r := rand.New(rand.NewSource(time.Now().UnixNano())) // TODO : What to do after 2262(UnixNano)???
+	//==============KEY BINDINGS==============
+	if settings.Bindings == "" {
+		path, err := BindingsPath()
+		if err != nil {
+			HandleError("Cannot find the config directory, the keys will not be saved: ", err)
+		}
+		settings.Bindings = path
+	}
+	bindings, err := LoadBindings(settings.Bindings)
+	if err != nil {
+		HandleError("Failed to load key bindings "+settings.Bindings+"(fix or remove it): ", err)
+		return ERROR_FAILED_TO_LOAD_BINDINGS
+	}
+
+	//==============CAMPAIGN==============
+	assets := NewAssets(settings.Assets)
+	campaign, err := LoadCampaign(assets, settings.Campaign)
+	if err != nil {
+		HandleError("Failed to load campaign "+settings.Campaign+": ", err)
+		return ERROR_FAILED_TO_LOAD_CAMPAIGN
+	}
+	if (*startLevel < 1) || (*startLevel > len(campaign.Levels)) {
+		HandleError("Failed to start the campaign:", fmt.Errorf("there is no level %d(the campaign has %d levels)", *startLevel, len(campaign.Levels)))
+		return ERROR_FAILED_TO_LOAD_CAMPAIGN
+	}
+	levelIndex := *startLevel - 1
+	if *levelPath != "" { // only one level, with the given map
+		levelSettings := campaign.Levels[levelIndex]
+		levelSettings.Map = *levelPath
+		campaign.Levels = []game.LevelSettings{levelSettings}
+		levelIndex = 0
+	}
+	levels, err := CampaignHash(assets, campaign)
+	if err != nil {
+		HandleError("Failed to load level: ", err)
+		return ERROR_FAILED_TO_LOAD_LEVEL
+	}
+	if (replay != nil) && (replay.Levels != [sha256.Size]byte{}) && (replay.Levels != levels) {
+		HandleError("Failed to play replay "+*replayPath+": ", errors.New("it was recorded with another campaign or map(see --campaign and --map)"))
+		return ERROR_FAILED_TO_READ_REPLAY
+	}
+
+	var recorder *game.ReplayRecorder
+	if *recordPath != "" {
+		recordFile, err := os.Create(*recordPath)
+		if err != nil {
+			HandleError("Failed to create replay "+*recordPath+": ", err)
+			return ERROR_FAILED_TO_WRITE_REPLAY
+		}
+		defer recordFile.Close()
+		recorder, err = game.NewReplayRecorder(recordFile, game.Replay{
+			Seed:         *seed,
+			Timestep:     settings.Timestep,
+			StartLevel:   *startLevel - 1,
+			Difficulty:   difficulty,
+			Players:      settings.Players,
+			Mode:         mode,
+			FriendlyFire: settings.FriendlyFire,
+			Rules:        settings.Rules,
+			Levels:       levels,
+		})
+		if err != nil {
+			HandleError("Failed to write replay "+*recordPath+": ", err)
+			return ERROR_FAILED_TO_WRITE_REPLAY
+		}
+		defer func() {
+			if err := recorder.Close(); err != nil {
+				HandleError("Failed to write replay "+*recordPath+": ", err)
+			}
+		}()
+	}
 
 	/* TODO : Most probably depricated
 	//==============SDL INIT==============
@@ -103,7 +242,7 @@ func run() int {
 
 	//==============CREATE WINDOW==============
 	window, err := sdl.CreateWindow(TITLE, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
-		SCREEN_WIDTH, SCREEN_HEIGHT, sdl.WINDOW_SHOWN)
+		settings.WindowWidth, settings.WindowHeight, sdl.WINDOW_SHOWN)
 	if err != nil {
 		HandleError("Failed to create window: ", err)
 		return ERROR_FAILED_TO_CREATE_WINDOW
@@ -112,7 +251,7 @@ func run() int {
 
 	//==============CREATE RENDERER==============
 	var renderer *sdl.Renderer
-	if VSYNC {
+	if settings.VSync {
 		renderer, err = sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
 	} else {
 		renderer, err = sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED)
@@ -122,10 +261,11 @@ func run() int {
 		return ERROR_FAILED_TO_CREATE_RENDERER
 	}
 	defer renderer.Destroy()
+	renderer.SetLogicalSize(SCREEN_WIDTH, SCREEN_HEIGHT) // the arena is always SCREEN_WIDTH x SCREEN_HEIGHT, scaled to the window
 
 	// sdl.SetHint(sdl.HINT_DEFAULT)
 
-	if SMOOTH_TEXTURES {
+	if settings.SmoothTextures {
 		sdl.SetHint(sdl.HINT_RENDER_SCALE_QUALITY, LINEAR)
 	}
 
@@ -135,294 +275,236 @@ func run() int {
 	}
 	defer mix.CloseAudio()
 
-	shootSoundEffect := GetSoundEffect(SHOOT_SOUND_PATH)
-	defer shootSoundEffect.Free()
-
-	explosionSoundEffect := GetSoundEffect(EXPLOSION_SOUND_PATH)
-	defer explosionSoundEffect.Free()
-
-	//==============PLAYER TANK==============
-	playerTankImage, playerTankTexture, errorCode := GetTexture(PLAYER_TANK_TEXTURE_PATH, renderer)
-	if errorCode != 0 {
-		return errorCode
+	//==============ASSET PACK==============
+	if settings.Mods == "" {
+		path, err := ModsPath()
+		if err != nil {
+			HandleError("Cannot find the config directory, there will be no mods: ", err)
+		}
+		settings.Mods = path
 	}
-	defer playerTankImage.Free()
-	defer playerTankTexture.Destroy()
-
-	playerTank := &PlayerTank{
-		tankTexture:   playerTankTexture,
-		rotationAngle: 0.0,
-		boundingBox: sdl.FRect{
-			X: (float32(SCREEN_WIDTH) / 2.0) - (float32(playerTankImage.W) / 2.0),  // positioning exactly at the centre of the screen
-			Y: (float32(SCREEN_HEIGHT) / 2.0) - (float32(playerTankImage.H) / 2.0), // positioning exactly at the centre of the screen
-			W: float32(playerTankImage.W),
-			H: float32(playerTankImage.H),
-		},
+	packs := FindAssetPacks(settings.Mods)
+	if settings.Pack == PACK_MENU {
+		index, ok := PackScreen(renderer, packs)
+		if !ok {
+			return 0
+		}
+		settings.Pack = packs[index].Name
 	}
-	var playerTankBullets []Bullet
-
-	//==============CALLBACKS==============
-	callbacks := map[sdl.Scancode]func(delta float32) *PlayerTank{
-		sdl.SCANCODE_LEFT:  playerTank.RotateAntiClockWise,
-		sdl.SCANCODE_RIGHT: playerTank.RotateClockWise,
-
-		sdl.SCANCODE_W: playerTank.MoveUp,
-		sdl.SCANCODE_A: playerTank.MoveLeft,
-		sdl.SCANCODE_S: playerTank.MoveDown,
-		sdl.SCANCODE_D: playerTank.MoveRight,
+	pack, err := FindAssetPack(packs, settings.Pack)
+	if err != nil {
+		HandleError("Using the default asset pack:", err)
+		pack = DefaultAssetPack()
 	}
+	fmt.Println("Asset pack: ", pack.Name)
+
+	sounds := LoadSoundEffects(assets, pack)
+	defer sounds.Free()
 
-	//==============ENEMY TANKS==============
-	enemyTankImage, enemyTankTexture, errorCode := GetTexture(ENEMY_TANK_TEXTURE_PATH, renderer)
+	textures, errorCode := LoadTextures(assets, renderer, pack)
 	if errorCode != 0 {
 		return errorCode
 	}
-	defer enemyTankImage.Free()
-	defer enemyTankTexture.Destroy()
+	defer textures.Destroy()
 
-	x := 2 + r.Intn(LEVEL_0_MAX_NUM_OF_ENEMY_TANKS/2) // Initially random num.of tanks will be alive(halving it so that the generated random no. is not too much), let us make at least 2 tanks alive at first
-	enemyTanks := make([]EnemyTank, x)
-	i := 0
-	for i = 0; i < x; i++ { // first x no.of tanks will be alive
-		if CRAZY_TANKS {
-			enemyTanks[i] = NewEnemyTank(enemyTankTexture, enemyTankImage.W, enemyTankImage.H, r.Float32()*360.0, GetRandomFloat32(0.0, 0.5, r))
-		} else {
-			enemyTanks[i] = NewEnemyTank(enemyTankTexture, enemyTankImage.W, enemyTankImage.H, r.Float32()*360.0, GetRandomFloat32(LEVEL_0_ENEMY_TANK_MIN_NO_UPDATES_TIME, LEVEL_0_ENEMY_TANK_MAX_NO_UPDATES_TIME, r))
+	if *benchBullets > 0 {
+		level, err := LoadLevel(assets, campaign.Levels[levelIndex].Map)
+		if err != nil {
+			HandleError("Failed to load level "+campaign.Levels[levelIndex].Map+": ", err)
+			return ERROR_FAILED_TO_LOAD_LEVEL
 		}
+		BenchmarkRendering(renderer, textures, level, *benchBullets, *seed)
+		return 0
 	}
 
-	numOfEnemyTanksSpawned := x
-
-	SetPositionOfEnemyTanks(enemyTanks, playerTank.boundingBox, r)
-	var enemyTankBullets []Bullet
+	//==============HIGH SCORES==============
+	if settings.HighScores == "" {
+		path, err := HighScoresPath()
+		if err != nil {
+			HandleError("Cannot find the data directory, the high scores will not be saved: ", err)
+		}
+		settings.HighScores = path
+	}
+	highScores, err := LoadHighScores(settings.HighScores)
+	if err != nil {
+		HandleError("Failed to load high scores "+settings.HighScores+"(fix or remove it), they will not be saved: ", err)
+		highScores, _ = LoadHighScores("")
+	}
+	// versus games, replays and single maps are not counted
+	countHighScores := (mode != game.MODE_VERSUS) && (replay == nil) && (*levelPath == "")
 
-	last := time.Now() // for calculating dt(delta)
-	var enemyTankSpawnTimer float32 = 0.0
-	fpsCounter := 0
-	var fpsTimer float32 = 0.0
+	//==============GAMEPADS==============
+	gamepads := NewGamepads(settings.Players, gamepadPlayers)
+	defer gamepads.Close()
 
-	//==============BULLET==============
-	bulletImage, bulletTexture, errorCode := GetTexture(BULLET_TEXTURE_PATH, renderer)
-	if errorCode != 0 {
-		return errorCode
-	}
-	defer bulletImage.Free()
-	defer bulletTexture.Destroy()
+	session := NewSession(renderer, textures, sounds, settings, bindings, gamepads, replay, recorder)
 
-	//==============EXPLOSION ANIMATION==============
-	var explosions []Explosion
-	explosionImage, explosionTexture, errorCode := GetTexture(EXPLOSION_ANIMATION_TEXTURE_PATH, renderer)
-	if errorCode != 0 {
-		return errorCode
+	//==============PLAYING THE CAMPAIGN==============
+	playerConfigs := make([]game.PlayerConfig, settings.Players)
+	for index, controller := range session.PlayerControllers() {
+		playerConfigs[index].Controller = controller
 	}
-	defer explosionImage.Free()
-	defer explosionTexture.Destroy()
-
-	//==============MAIN LOOP==============
-	keyboardState := sdl.GetKeyboardState() // for handling keyboard events
-	running := true                         // Main loop flag
-	playerShootedInLastFrame := false       // just a flag, to manage player tank shoot events(it ensures that the player tank will not shoot continuously, on pressing down 'space')
-	for running {
-
-		//==============CALCULATING dt(DELTA)==============
-		dt := float32(time.Since(last).Seconds())
-		last = time.Now()
-
-		//==============PRINTING FPS==============
-		fpsTimer += dt
-		if fpsTimer >= 1.0 {
-			fmt.Println("Current FPS: ", fpsCounter)
-			fpsTimer = 0.0
-			fpsCounter = 0
+	roundsWon := make([]int, settings.Players)    // in versus, every level is a round
+	stats := make([]game.Stats, settings.Players) // of the levels played so far
+	for ; levelIndex < len(campaign.Levels); levelIndex++ {
+		levelSettings := campaign.Levels[levelIndex]
+		level, err := LoadLevel(assets, levelSettings.Map)
+		if err != nil {
+			HandleError("Failed to load level "+levelSettings.Map+": ", err)
+			return ERROR_FAILED_TO_LOAD_LEVEL
 		}
-
-		//==============CHECKING WHETHER PLAYER HAS WON==============
-		if (len(enemyTanks) == 0) /*if all the tanks has been destroyed by the player, and*/ &&
-			(numOfEnemyTanksSpawned == LEVEL_0_MAX_NUM_OF_ENEMY_TANKS) /*if all the tanks has been spawned*/ {
-			// TODO: Render text, instead of printing inside the console
-			fmt.Println("==============PLAYER WON==============")
-			sdl.Delay(1000)
-			break
+		world := game.NewWorld(game.Config{
+			ArenaWidth:      float32(SCREEN_WIDTH),
+			ArenaHeight:     float32(SCREEN_HEIGHT),
+			PlayerTankSize:  game.Size{W: game.DEFAULT_TANK_WIDTH, H: game.DEFAULT_TANK_HEIGHT}, // the textures of the pack are scaled to these sizes
+			EnemyTankSize:   game.Size{W: game.DEFAULT_TANK_WIDTH, H: game.DEFAULT_TANK_HEIGHT},
+			BulletSize:      game.Size{W: game.DEFAULT_BULLET_WIDTH, H: game.DEFAULT_BULLET_HEIGHT},
+			ExplosionFrames: game.DEFAULT_EXPLOSION_FRAMES,
+			Level:           level,
+			LevelSettings:   levelSettings,
+			Difficulty:      game.DIFFICULTIES[difficulty],
+			Players:         playerConfigs,
+			Mode:            mode,
+			FriendlyFire:    settings.FriendlyFire,
+			Rules:           settings.Rules,
+		}, *seed+int64(levelIndex)) // every level has it's own seed, otherwise they would start in the same way
+
+		//==============INTERMISSION==============
+		lines := []string{fmt.Sprintf("LEVEL %d", levelIndex+1), levelSettings.Name, ""}
+		if mode == game.MODE_VERSUS {
+			lines[0] = fmt.Sprintf("ROUND %d", levelIndex+1)
 		}
-
-		//==============SPAWNING NEW ENEMY TANKS==============
-		enemyTankSpawnTimer += dt
-		if (enemyTankSpawnTimer >= LEVEL_0_ENEMY_SPAWN_OFF_TIME) && (numOfEnemyTanksSpawned < LEVEL_0_MAX_NUM_OF_ENEMY_TANKS) {
-			if CRAZY_TANKS {
-				enemyTanks = append(enemyTanks, NewEnemyTank(enemyTankTexture, enemyTankImage.W, enemyTankImage.H, r.Float32()*360.0, GetRandomFloat32(0.0, 0.5, r)))
+		for index := range world.Players {
+			if settings.Players == 1 {
+				lines = append(lines, fmt.Sprintf("LIVES: %d", world.Players[index].Tank.Lives))
 			} else {
-				enemyTanks = append(enemyTanks, NewEnemyTank(enemyTankTexture, enemyTankImage.W, enemyTankImage.H, r.Float32()*360.0, GetRandomFloat32(LEVEL_0_ENEMY_TANK_MIN_NO_UPDATES_TIME, LEVEL_0_ENEMY_TANK_MAX_NO_UPDATES_TIME, r)))
+				lines = append(lines, fmt.Sprintf("P%d LIVES: %d", index+1, world.Players[index].Tank.Lives))
 			}
-			IndexOfLastEnemyTank := len(enemyTanks) - 1
-			enemyTanks[IndexOfLastEnemyTank].boundingBox = GetPositionOfOneEnemyTank(enemyTanks[IndexOfLastEnemyTank].boundingBox, enemyTanks[:IndexOfLastEnemyTank], playerTank.boundingBox, r)
-			enemyTankSpawnTimer = 0.0
-			numOfEnemyTanksSpawned += 1
 		}
-
-		//==============UPDATING ENEMY TANKS==============
-		var bullet Bullet
-		var experimentalEnemyTank EnemyTank
-		for index := range enemyTanks {
-
-			//==============UPDATING ANIMATION(ON EVERY FRAME)==============
-			enemyTanks[index].UpdateAnimation(dt)
-
-			//==============UPDATING POSITION AND ROTATION, SHOOTING BULLETS==============
-			if enemyTanks[index].WillUpdate() { // updating based on an update timer
-				switch r.Intn(3) {
-				case 0:
-					experimentalEnemyTank = enemyTanks[index].MoveInRandomDir(dt, r)
-					if ValidPosition(experimentalEnemyTank.boundingBox, enemyTanks, playerTank.boundingBox) {
-						enemyTanks[index].boundingBox = experimentalEnemyTank.boundingBox
-					}
-				case 1:
-					enemyTanks[index].Rotate(r, sdl.FPoint{
-						X: playerTank.boundingBox.X,
-						Y: playerTank.boundingBox.Y,
-					})
-				case 2:
-					bullet = enemyTanks[index].Shoot(bulletTexture, bulletImage.W, bulletImage.H)
-					enemyTankBullets = append(enemyTankBullets, bullet)
-					PlaySoundEffect(shootSoundEffect)
-				}
-			}
+		if mode != game.MODE_VERSUS {
+			lines = append(lines, fmt.Sprintf("ENEMY TANKS: %d", levelSettings.MaxNumOfEnemyTanks))
 		}
-
-		//==============UPDATING ENEMY TANK BULLETS==============
-		for index := range enemyTankBullets {
-			enemyTankBullets[index].Update(dt)
+		if !ShowScreen(renderer, settings.IntermissionTime, replay == nil, lines...) {
+			return 0
 		}
 
-		//==============OPTIMIZATON(removing the bullets, which are out of the window)==============
-		// range over slice will not work, as:
-		// for i, _ := range ...{...}, here the maximum value of i is the length of the slice
-		// i is initialized with length of the slice, but it doesn't assert new value of that length, when the length of that slice changes
-		// for i := 0; i < len(...); i++ {...} in this kind of loop the ;len(...); condition is always checked
-		for i := 0; i < len(playerTankBullets); i++ {
-			if !IsInsideWindow(playerTankBullets[i].boundingBox) {
-				playerTankBullets = RemoveElementFromBulletSlice(playerTankBullets, i)
-			}
+		result := session.PlayLevel(world)
+		for index := range world.Players {
+			playerConfigs[index].Score = world.Players[index].Score
+			stats[index] = stats[index].Add(world.Players[index].Stats)
 		}
-		for i := 0; i < len(enemyTankBullets); i++ {
-			if !IsInsideWindow(enemyTankBullets[i].boundingBox) {
-				enemyTankBullets = RemoveElementFromBulletSlice(enemyTankBullets, i)
-			}
-		}
-
-		//==============EVENT HANDLING==============
-		for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
-			if event.GetType() == sdl.QUIT {
-				running = false
+		switch result {
+		case LEVEL_QUIT:
+			return 0
+		case LEVEL_LOST:
+			fmt.Println("==============PLAYER LOST==============")
+			ShowScreen(renderer, settings.GameOverScreenTime, false, append([]string{"GAME OVER", "", fmt.Sprintf("LEVEL %d", levelIndex+1)}, ScoreLines(playerConfigs)...)...)
+			if countHighScores {
+				ShowResults(renderer, settings.ResultsScreenTime, highScores, playerConfigs, stats, levelIndex+1)
 			}
-			switch t := event.(type) {
-			case *sdl.KeyboardEvent:
-				if t.Keysym.Sym == sdl.K_SPACE {
-					if event.GetType() == sdl.KEYDOWN {
-						if !playerShootedInLastFrame {
-							playerTankBullets = append(playerTankBullets, playerTank.Shoot(bulletTexture, bulletImage.W, bulletImage.H))
-							PlaySoundEffect(shootSoundEffect)
-							playerShootedInLastFrame = true
-						}
-					}
-					if event.GetType() == sdl.KEYUP {
-						playerShootedInLastFrame = false
-					}
-				}
-			}
-		}
-
-		// sdl.PumpEvents() // not required
-
-		if keyboardState[sdl.SCANCODE_ESCAPE] == 1 {
-			running = false
-		}
-
-		for key, callbackFunc := range callbacks {
-			if keyboardState[key] == 1 {
-				intersect := false
-				experimentalPlayerTank := callbackFunc(dt)
-				// collision detection with enemy tanks and window
-				for index := range enemyTanks {
-					if enemyTanks[index].boundingBox.HasIntersection(&experimentalPlayerTank.boundingBox) {
-						intersect = true
-						break
-					}
+			return PLAYER_LOST
+		case LEVEL_WON:
+			if mode == game.MODE_VERSUS { // every round starts with full lives
+				line := "DRAW"
+				if world.Winner >= 0 {
+					roundsWon[world.Winner] += 1
+					line = fmt.Sprintf("PLAYER %d WINS THE ROUND", world.Winner+1)
 				}
-				// if no collision with enemy tanks and window
-				if !intersect && IsInsideWindow(experimentalPlayerTank.boundingBox) {
-					// In the callbacks map, if they were declared like: playerTank.moveDown, where playerTank is an actual value, not a pointer to playerTank, then
-					// the callback functions are 'bound' to that playerTank, with which they were initialized, changing the playerTank will not change the playerTank with
-					// which they were initialized, so I used playerTank as a pointer.
-
-					// TODO : playerTank = experimentalPlayerTank, will not work, why?
-					playerTank.boundingBox = experimentalPlayerTank.boundingBox
-					playerTank.rotationAngle = experimentalPlayerTank.rotationAngle
+				if !ShowScreen(renderer, settings.IntermissionTime, replay == nil, append([]string{line, ""}, ScoreLines(playerConfigs)...)...) {
+					return 0
 				}
+				continue
 			}
-		}
-
-		//==============UPDATING PLAYER TANK BULLETS==============
-		for index := range playerTankBullets {
-			playerTankBullets[index].Update(dt)
-		}
-
-		//==============DESTROYING ENEMY TANKS(by player tank bullets)==============
-		for index := range playerTankBullets {
-			for i := 0; i < len(enemyTanks); i++ {
-				bulletNosePosition := sdl.FPoint{
-					playerTankBullets[index].boundingBox.X + playerTankBullets[index].boundingBox.W,
-					playerTankBullets[index].boundingBox.Y + (playerTankBullets[index].boundingBox.H / 2.0),
-				}
-				if bulletNosePosition.InRect(&enemyTanks[i].boundingBox) {
-					explosions = append(explosions, NewExplosion(enemyTanks[i].boundingBox, explosionTexture))
-					enemyTanks = RemoveElementFromEnemyTankSlice(enemyTanks, i)
-					PlaySoundEffect(explosionSoundEffect)
+			for index := range world.Players {
+				playerConfigs[index].Lives = world.Players[index].Tank.Lives
+				if world.Players[index].Lost { // a lost player comes back in the next level, with one life
+					playerConfigs[index].Lives = 1
 				}
 			}
+			if !ShowScreen(renderer, settings.IntermissionTime, replay == nil, append(LevelResultLines(world, levelIndex), ScoreLines(playerConfigs)...)...) {
+				return 0
+			}
 		}
+	}
 
-		//==============REMOVING DIED EXPLOSION ANIMATIONS==============
-		for i := 0; i < len(explosions); i++ {
-			if explosions[i].died {
-				explosions = RemoveElementFromExplosionSlice(explosions, i)
+	if mode == game.MODE_VERSUS {
+		winner, draw := 0, false
+		for index := range roundsWon {
+			if roundsWon[index] > roundsWon[winner] {
+				winner, draw = index, false
+			} else if (index != winner) && (roundsWon[index] == roundsWon[winner]) {
+				draw = true
 			}
 		}
-
-		//==============UPDATING EXPLOSION ANIMATIONS==============
-		for index := range explosions {
-			explosions[index].Update()
+		line := fmt.Sprintf("PLAYER %d WON!", winner+1)
+		if draw {
+			line = "DRAW!"
 		}
+		fmt.Println("==============" + line + "==============")
+		ShowScreen(renderer, settings.GameOverScreenTime, false, append([]string{line, ""}, ScoreLines(playerConfigs)...)...)
+		return 0
+	}
 
-		// TODO : Update first, or draw first?(most probably update first) + update order
-		//		playerTank.Update()
+	fmt.Println("==============PLAYER WON==============")
+	ShowScreen(renderer, settings.GameOverScreenTime, false, append([]string{"YOU WON!", "", "ALL LEVELS CLEARED"}, ScoreLines(playerConfigs)...)...)
+	if countHighScores {
+		ShowResults(renderer, settings.ResultsScreenTime, highScores, playerConfigs, stats, len(campaign.Levels))
+	}
 
-		//==============CLEARING THE SCREEN==============
-		renderer.SetDrawColor(colornames.Bisque.R, colornames.Bisque.G, colornames.Bisque.B, colornames.Bisque.A)
-		renderer.Clear()
-		renderer.SetDrawColor(colornames.Red.R, colornames.Red.G, colornames.Red.B, colornames.Red.A)
+	//sdl.Quit()
+	return 0
+}
 
-		//==============DRAWING==============
-		for index := range explosions {
-			explosions[index].Draw(renderer)
-		}
-		DrawTexture(renderer, playerTank.tankTexture, &playerTank.boundingBox, playerTank.rotationAngle)
-		for index := range playerTankBullets {
-			DrawTexture(renderer, playerTankBullets[index].bulletTexture, &playerTankBullets[index].boundingBox, playerTankBullets[index].rotationAngle)
-		}
-		for index := range enemyTanks {
-			DrawTexture(renderer, enemyTanks[index].tankTexture, &enemyTanks[index].boundingBox, enemyTanks[index].rotationAngle)
-		}
-		for index := range enemyTankBullets {
-			DrawTexture(renderer, enemyTankBullets[index].bulletTexture, &enemyTankBullets[index].boundingBox, enemyTankBullets[index].rotationAngle)
-		}
-		renderer.Present()
+// score of every player, for the screens
+func ScoreLines(players []game.PlayerConfig) []string {
+	if len(players) == 1 {
+		return []string{fmt.Sprintf("SCORE: %d", players[0].Score)}
+	}
+	var lines []string
+	for index := range players {
+		lines = append(lines, fmt.Sprintf("P%d SCORE: %d", index+1, players[index].Score))
+	}
+	return lines
+}
 
-		//==============UPDATING FPS COUNTER==============
-		fpsCounter += 1
+// the results of every player, for the screen after the game
+func ResultLines(players []game.PlayerConfig, stats []game.Stats) []string {
+	lines := []string{"RESULTS", ""}
+	if len(players) == 1 {
+		return append(lines,
+			fmt.Sprintf("SCORE: %d", players[0].Score),
+			fmt.Sprintf("KILLS: %d", stats[0].Kills),
+			fmt.Sprintf("ACCURACY: %.0f%%", stats[0].Accuracy()*100.0),
+			fmt.Sprintf("BEST STREAK: %d", stats[0].BestStreak),
+			fmt.Sprintf("BONUSES: %d", stats[0].TimeBonus+stats[0].AccuracyBonus),
+		)
+	}
+	for index := range players {
+		lines = append(lines,
+			fmt.Sprintf("P%d SCORE: %d", index+1, players[index].Score),
+			fmt.Sprintf("KILLS:%d ACCURACY:%.0f%% STREAK:%d BONUSES:%d", stats[index].Kills, stats[index].Accuracy()*100.0, stats[index].BestStreak, stats[index].TimeBonus+stats[index].AccuracyBonus),
+		)
 	}
+	return lines
+}
 
-	//sdl.Quit()
-	return 0
+// the time and the bonuses of the won level(see score.go of the game package), the scores go under them
+func LevelResultLines(world *game.World, levelIndex int) []string {
+	seconds := int(world.Time)
+	lines := []string{fmt.Sprintf("LEVEL %d CLEARED", levelIndex+1), "", fmt.Sprintf("TIME: %d:%02d", seconds/60, seconds%60)}
+	for index := range world.Players {
+		player := &world.Players[index]
+		if len(world.Players) == 1 {
+			lines = append(lines,
+				fmt.Sprintf("ACCURACY: %.0f%%", player.Stats.Accuracy()*100.0),
+				fmt.Sprintf("TIME BONUS: %d", player.Stats.TimeBonus),
+				fmt.Sprintf("ACCURACY BONUS: %d", player.Stats.AccuracyBonus),
+			)
+		} else {
+			lines = append(lines, fmt.Sprintf("P%d TIME BONUS:%d ACCURACY BONUS:%d", index+1, player.Stats.TimeBonus, player.Stats.AccuracyBonus))
+		}
+	}
+	return append(lines, "")
 }
 
 func main() {
@@ -0,0 +1,115 @@
+// mouse.go
+package main
+
+import (
+	"math"
+
+	"github.com/dev-abir/tanks/game"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+/*
+
+The mouse aims the tank of the first player: the tank(or it's turret, with tank controls) turns towards
+the crosshair, the left button shoots, and holding it shoots again every autofireInterval seconds.
+
+The mouse aims only after it has moved, and the rotate keys take the aim back to the keyboard(until the mouse
+moves again), so the game can still be played with the keyboard only.
+The aim goes into the input like the right stick of a gamepad(Input.Aim), so the replays have it too.
+
+*/
+
+const (
+	MOUSE_PLAYER      int     = 0    // index of the player, who plays with the mouse
+	AUTOFIRE_INTERVAL float32 = 0.25 // seconds, between the shots while the button is held
+	CROSSHAIR_SIZE    float32 = 24   // pixels
+)
+
+type Mouse struct {
+	enabled  bool
+	interval float32    // seconds, between the shots while the button is held
+	active   bool       // the mouse aims, it has moved since the last rotate key
+	position game.Point // of the cursor, in the arena(the renderer has a logical size, so the events are in the arena already)
+	clicked  bool       // the button was pressed, but the world has not been stepped yet
+	held     bool
+	autofire float32 // seconds until the next shot, while the button is held
+}
+
+func NewMouse(enabled bool, interval float32) *Mouse {
+	return &Mouse{enabled: enabled, interval: interval}
+}
+
+// handles the events of the mouse, returns false if the event is not about it
+func (mouse *Mouse) HandleEvent(event sdl.Event) bool {
+	if !mouse.enabled {
+		return false
+	}
+	switch t := event.(type) {
+	case *sdl.MouseMotionEvent:
+		mouse.position = game.Point{X: float32(t.X), Y: float32(t.Y)}
+		mouse.active = true
+	case *sdl.MouseButtonEvent:
+		if t.Button != sdl.BUTTON_LEFT {
+			return true
+		}
+		mouse.position = game.Point{X: float32(t.X), Y: float32(t.Y)}
+		mouse.active = true
+		if t.State == sdl.PRESSED {
+			mouse.clicked = true
+			mouse.held = true
+			mouse.autofire = mouse.interval
+		} else {
+			mouse.held = false
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// the button is released(the level starts, or a screen took the events)
+func (mouse *Mouse) Reset() {
+	mouse.clicked = false
+	mouse.held = false
+}
+
+/*Adds the mouse to the input of the keyboard(and the gamepad) of the tank.
+Call it once in every frame, dt is the real time of the frame(for the autofire).*/
+func (mouse *Mouse) Input(tank *game.Tank, input game.Input, dt float32) game.Input {
+	if !mouse.enabled {
+		return input
+	}
+	if input.RotateAntiClockWise || input.RotateClockWise {
+		mouse.active = false
+	}
+	if mouse.active {
+		centre := tank.BoundingBox.Centre()
+		input.Aim = true
+		// the same angles as the tanks, 0 is right and the angle grows clock wise(y grows downwards)
+		input.AimAngle = game.QuantizeAngle(float32(math.Atan2(float64(mouse.position.Y-centre.Y), float64(mouse.position.X-centre.X)) * 180.0 / math.Pi))
+	}
+	if mouse.clicked {
+		input.Shoot = true
+		mouse.clicked = false
+	} else if mouse.held {
+		mouse.autofire -= dt
+		if mouse.autofire <= 0.0 {
+			input.Shoot = true
+			mouse.autofire = mouse.interval
+		}
+	}
+	return input
+}
+
+// the crosshair is drawn while the mouse aims
+func (mouse *Mouse) DrawCrosshair(batch *Batch, textures Textures) {
+	if !mouse.enabled || !mouse.active {
+		return
+	}
+	batch.Add(textures.crosshair, game.Rect{
+		X: mouse.position.X - (CROSSHAIR_SIZE / 2.0),
+		Y: mouse.position.Y - (CROSSHAIR_SIZE / 2.0),
+		W: CROSSHAIR_SIZE,
+		H: CROSSHAIR_SIZE,
+	}, 0.0, NO_TINT)
+}
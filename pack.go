@@ -0,0 +1,358 @@
+// pack.go
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/dev-abir/tanks/game"
+	"github.com/veandco/go-sdl2/mix"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+/*
+
+An asset pack changes how the game looks and sounds. A pack is a directory in the mods directory
+(tanks/mods in the config directory of the user, or the setting "mods"), with a pack.json manifest:
+
+	{
+		"name": "Desert",
+		"textures": {"playerTank": "player.png", "enemyTank": "enemy.png", "bullet": "bullet.png", "tiles": "tiles.png", "explosion": "boom.png"},
+		"tileSize": 32,
+		"sounds": {"shoot": "shoot.ogg", "explosion": "boom.wav"}
+	}
+
+The files are relative to the pack directory, anything missing is taken from the default pack.
+The playerTankHull and turret textures are drawn instead of playerTank with tank controls(see game.Rules.TankControls),
+the turret is as big as the tank, and it is turned around it's centre. The crosshair is drawn at the mouse, centred.
+The tiles texture has brick, steel, water and trees(in the order of game.Tile) from left to right, tileSize pixels each,
+with the damaged tiles in the second row. The animated textures(explosion, muzzleFlash, treads and sparkle) are
+sprite sheets, with their sidecar files next to them(boom.png -> boom.json, see sprites.go).
+
+A pack does not change the game itself: the tanks and the bullets are as big as they always are(the textures are scaled),
+so the replays play the same with any pack. Broken files(missing, or with the wrong size) are reported,
+and the files of the default pack are used instead of them.
+
+*/
+
+const (
+	DEFAULT_PACK_NAME    string  = "default"
+	PACK_MENU            string  = "menu" // the setting "pack", for choosing the pack on a screen
+	PACK_MANIFEST        string  = "pack.json"
+	PACK_ASPECT_RATIO    float64 = 1.25 // textures of the tanks and the bullets may be this much wider or taller, than in the game
+	TILE_TEXTURE_COLUMNS int32   = 4    // brick, steel, water and trees
+	TILE_TEXTURE_ROWS    int32   = 2    // the damaged tiles in the second row
+)
+
+type AssetPack struct {
+	Name     string `json:"name"`
+	Textures struct {
+		PlayerTank     string `json:"playerTank"`
+		PlayerTankHull string `json:"playerTankHull"`
+		Turret         string `json:"turret"`
+		Crosshair      string `json:"crosshair"`
+		EnemyTank      string `json:"enemyTank"`
+		Bullet         string `json:"bullet"`
+		Tiles          string `json:"tiles"`
+
+		// sprite sheets
+		Explosion   string `json:"explosion"`
+		MuzzleFlash string `json:"muzzleFlash"`
+		Treads      string `json:"treads"`
+		Sparkle     string `json:"sparkle"`
+	} `json:"textures"`
+	TileSize int32 `json:"tileSize"` // pixels, of one tile in the tiles texture
+	Sounds   struct {
+		Shoot     string `json:"shoot"`
+		Explosion string `json:"explosion"`
+	} `json:"sounds"`
+}
+
+func DefaultAssetPack() AssetPack {
+	var pack AssetPack
+	pack.Name = DEFAULT_PACK_NAME
+	pack.Textures.PlayerTank = PLAYER_TANK_TEXTURE_PATH
+	pack.Textures.PlayerTankHull = PLAYER_TANK_HULL_TEXTURE_PATH
+	pack.Textures.Turret = TURRET_TEXTURE_PATH
+	pack.Textures.Crosshair = CROSSHAIR_TEXTURE_PATH
+	pack.Textures.EnemyTank = ENEMY_TANK_TEXTURE_PATH
+	pack.Textures.Bullet = BULLET_TEXTURE_PATH
+	pack.Textures.Tiles = TILES_TEXTURE_PATH
+	pack.Textures.Explosion = EXPLOSION_ANIMATION_TEXTURE_PATH
+	pack.Textures.MuzzleFlash = MUZZLE_FLASH_TEXTURE_PATH
+	pack.Textures.Treads = TREADS_TEXTURE_PATH
+	pack.Textures.Sparkle = SPARKLE_TEXTURE_PATH
+	pack.TileSize = TILE_CELL_SIZE
+	pack.Sounds.Shoot = SHOOT_SOUND_PATH
+	pack.Sounds.Explosion = EXPLOSION_SOUND_PATH
+	return pack
+}
+
+// the mods directory in the config directory of the user(for example ~/.config/tanks/mods on linux)
+func ModsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, USER_DIRECTORY, "mods"), nil
+}
+
+/*Loads the manifest of the pack in this directory. The files of the pack are resolved to their full(absolute) paths,
+and the missing ones are taken from the default pack.*/
+func LoadAssetPack(dir string) (AssetPack, error) {
+	// Assets.ReadFile reads only absolute paths directly, a relative one would be looked up in the asset directories
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return AssetPack{}, err
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, PACK_MANIFEST))
+	if err != nil {
+		return AssetPack{}, err
+	}
+	var manifest AssetPack
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return AssetPack{}, fmt.Errorf("%s: %v", filepath.Join(dir, PACK_MANIFEST), err)
+	}
+
+	pack := DefaultAssetPack()
+	pack.Name = filepath.Base(dir)
+	if manifest.Name != "" {
+		pack.Name = manifest.Name
+	}
+	for _, file := range []struct {
+		value  string
+		result *string
+	}{
+		{manifest.Textures.PlayerTank, &pack.Textures.PlayerTank},
+		{manifest.Textures.PlayerTankHull, &pack.Textures.PlayerTankHull},
+		{manifest.Textures.Turret, &pack.Textures.Turret},
+		{manifest.Textures.Crosshair, &pack.Textures.Crosshair},
+		{manifest.Textures.EnemyTank, &pack.Textures.EnemyTank},
+		{manifest.Textures.Bullet, &pack.Textures.Bullet},
+		{manifest.Textures.Tiles, &pack.Textures.Tiles},
+		{manifest.Textures.Explosion, &pack.Textures.Explosion},
+		{manifest.Textures.MuzzleFlash, &pack.Textures.MuzzleFlash},
+		{manifest.Textures.Treads, &pack.Textures.Treads},
+		{manifest.Textures.Sparkle, &pack.Textures.Sparkle},
+		{manifest.Sounds.Shoot, &pack.Sounds.Shoot},
+		{manifest.Sounds.Explosion, &pack.Sounds.Explosion},
+	} {
+		if file.value != "" {
+			*file.result = filepath.Join(dir, filepath.FromSlash(file.value))
+		}
+	}
+
+	if manifest.Textures.Tiles != "" {
+		if manifest.TileSize <= 0 {
+			return AssetPack{}, errors.New("tileSize is needed with the tiles texture")
+		}
+		pack.TileSize = manifest.TileSize
+	}
+	return pack, nil
+}
+
+// the default pack, and every pack in the mods directory(sorted by name), the broken packs are reported and skipped
+func FindAssetPacks(modsDir string) []AssetPack {
+	packs := []AssetPack{DefaultAssetPack()}
+	if modsDir == "" {
+		return packs
+	}
+	modsDir, err := filepath.Abs(modsDir) // --mods ./mods is relative to the working directory
+	if err != nil {
+		HandleError("Cannot read the mods directory:", err)
+		return packs
+	}
+	entries, err := ioutil.ReadDir(modsDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			HandleError("Cannot read the mods directory:", err)
+		}
+		return packs
+	}
+	var mods []AssetPack
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		pack, err := LoadAssetPack(filepath.Join(modsDir, entry.Name()))
+		if os.IsNotExist(err) {
+			continue // not a pack
+		} else if err != nil {
+			HandleError("Broken asset pack "+entry.Name()+":", err)
+			continue
+		}
+		mods = append(mods, pack)
+	}
+	sort.Slice(mods, func(i, j int) bool { return mods[i].Name < mods[j].Name })
+	return append(packs, mods...)
+}
+
+// the pack with this name(or the name of it's directory)
+func FindAssetPack(packs []AssetPack, name string) (AssetPack, error) {
+	for _, pack := range packs {
+		if pack.Name == name {
+			return pack, nil
+		}
+	}
+	var names []string
+	for _, pack := range packs {
+		names = append(names, pack.Name)
+	}
+	return AssetPack{}, fmt.Errorf("there is no asset pack %q(the packs are %v)", name, names)
+}
+
+//==============LOADING==============
+
+// checks the size of an image for a texture, size is the size of the texture in the game(zero for any size)
+func validateImage(image *sdl.Surface, size game.Size) error {
+	if (image.W <= 0) || (image.H <= 0) {
+		return errors.New("empty image")
+	}
+	if size.W == 0 {
+		return nil
+	}
+	ratio := (float64(image.W) / float64(image.H)) / (float64(size.W) / float64(size.H))
+	if (ratio > PACK_ASPECT_RATIO) || (ratio < 1.0/PACK_ASPECT_RATIO) {
+		return fmt.Errorf("the image is %dx%d, it should have the shape of %vx%v", image.W, image.H, size.W, size.H)
+	}
+	return nil
+}
+
+/*Loads the image of the pack, or else(if it is broken) the image of the default pack.
+check validates the image(of either pack).*/
+func loadPackImage(assets *Assets, path string, defaultPath string, check func(path string, image *sdl.Surface) error) (*sdl.Surface, int) {
+	load := func(path string) (*sdl.Surface, error) {
+		image, err := assets.LoadImage(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := check(path, image); err != nil {
+			image.Free()
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		return image, nil
+	}
+	image, err := load(path)
+	if (err != nil) && (path != defaultPath) {
+		HandleError("Broken texture, using the default one:", err)
+		image, err = load(defaultPath)
+	}
+	if err != nil {
+		HandleError("Failed to load image:", err)
+		return nil, ERROR_FAILED_TO_LOAD_IMAGE
+	}
+	return image, 0
+}
+
+// loads the textures of the pack into the atlas, the broken ones are replaced by the default ones
+func LoadTextures(assets *Assets, renderer *sdl.Renderer, pack AssetPack) (Textures, int) {
+	var textures Textures
+	var images []*sdl.Surface
+	var regions []*sdl.Rect
+	defaults := DefaultAssetPack()
+	tankSize := game.Size{W: game.DEFAULT_TANK_WIDTH, H: game.DEFAULT_TANK_HEIGHT}
+	bulletSize := game.Size{W: game.DEFAULT_BULLET_WIDTH, H: game.DEFAULT_BULLET_HEIGHT}
+	sizeOf := func(size game.Size) func(string, *sdl.Surface) error {
+		return func(path string, image *sdl.Surface) error { return validateImage(image, size) }
+	}
+	sheetOf := func(sprite *Sprite, name string) func(string, *sdl.Surface) error {
+		return func(path string, image *sdl.Surface) error {
+			sheet, err := LoadSpriteSheet(assets, path, image, name)
+			sprite.sheet = sheet
+			return err
+		}
+	}
+
+	for _, t := range []struct {
+		path        string
+		defaultPath string
+		check       func(string, *sdl.Surface) error
+		region      *sdl.Rect // in the atlas
+	}{
+		{pack.Textures.PlayerTank, defaults.Textures.PlayerTank, sizeOf(tankSize), &textures.playerTank},
+		{pack.Textures.PlayerTankHull, defaults.Textures.PlayerTankHull, sizeOf(tankSize), &textures.playerTankHull},
+		{pack.Textures.Turret, defaults.Textures.Turret, sizeOf(tankSize), &textures.turret},
+		{pack.Textures.Crosshair, defaults.Textures.Crosshair, sizeOf(game.Size{W: CROSSHAIR_SIZE, H: CROSSHAIR_SIZE}), &textures.crosshair},
+		{pack.Textures.EnemyTank, defaults.Textures.EnemyTank, sizeOf(tankSize), &textures.enemyTank},
+		{pack.Textures.Bullet, defaults.Textures.Bullet, sizeOf(bulletSize), &textures.bullet},
+		{pack.Textures.Tiles, defaults.Textures.Tiles, func(path string, image *sdl.Surface) error {
+			tileSize := pack.TileSize
+			if path != pack.Textures.Tiles {
+				tileSize = defaults.TileSize // the default texture
+			}
+			if (image.W < TILE_TEXTURE_COLUMNS*tileSize) || (image.H < TILE_TEXTURE_ROWS*tileSize) {
+				return fmt.Errorf("the image is %dx%d, it should be at least %dx%d(%dx%d tiles of %d pixels)", image.W, image.H,
+					TILE_TEXTURE_COLUMNS*tileSize, TILE_TEXTURE_ROWS*tileSize, TILE_TEXTURE_COLUMNS, TILE_TEXTURE_ROWS, tileSize)
+			}
+			textures.tileSize = tileSize
+			return nil
+		}, &textures.tiles},
+		{pack.Textures.Explosion, defaults.Textures.Explosion, sheetOf(&textures.explosion, ANIMATION_EXPLOSION), &textures.explosion.region},
+		{pack.Textures.MuzzleFlash, defaults.Textures.MuzzleFlash, sheetOf(&textures.muzzleFlash, ANIMATION_MUZZLE_FLASH), &textures.muzzleFlash.region},
+		{pack.Textures.Treads, defaults.Textures.Treads, sheetOf(&textures.treads, ANIMATION_TREADS), &textures.treads.region},
+		{pack.Textures.Sparkle, defaults.Textures.Sparkle, sheetOf(&textures.sparkle, ANIMATION_SPARKLE), &textures.sparkle.region},
+	} {
+		image, errorCode := loadPackImage(assets, t.path, t.defaultPath, t.check)
+		if errorCode != 0 {
+			return textures, errorCode
+		}
+		defer image.Free()
+		images = append(images, image)
+		regions = append(regions, t.region)
+	}
+
+	atlas, places, err := BuildAtlas(renderer, images)
+	if err != nil {
+		HandleError("Failed to create the texture atlas: ", err)
+		return textures, ERROR_FAILED_TO_CREATE_TEXTURE_FROM_IMAGE
+	}
+	textures.atlas = atlas
+	for index := range regions {
+		*regions[index] = places[index]
+	}
+	return textures, 0
+}
+
+func (textures *Textures) Destroy() {
+	if textures.atlas != nil {
+		textures.atlas.Destroy()
+	}
+}
+
+// loads the sounds of the pack, the broken ones are replaced by the default ones(a missing sound is not an error, the game is played without it)
+func LoadSoundEffects(assets *Assets, pack AssetPack) SoundEffects {
+	var sounds SoundEffects
+	defaults := DefaultAssetPack()
+	for _, s := range []struct {
+		path        string
+		defaultPath string
+		chunk       **mix.Chunk
+	}{
+		{pack.Sounds.Shoot, defaults.Sounds.Shoot, &sounds.shoot},
+		{pack.Sounds.Explosion, defaults.Sounds.Explosion, &sounds.explosion},
+	} {
+		chunk, err := assets.LoadSound(s.path)
+		if (err != nil) && (s.path != s.defaultPath) {
+			HandleError("Broken sound, using the default one:", err)
+			chunk, err = assets.LoadSound(s.defaultPath)
+		}
+		if err != nil {
+			HandleError("Cannot load "+s.defaultPath+", you may play without it: ", err)
+		}
+		*s.chunk = chunk
+	}
+	return sounds
+}
+
+func (sounds *SoundEffects) Free() {
+	for _, chunk := range []*mix.Chunk{sounds.shoot, sounds.explosion} {
+		if chunk != nil {
+			chunk.Free()
+		}
+	}
+}
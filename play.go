@@ -0,0 +1,421 @@
+// play.go
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"golang.org/x/image/colornames"
+
+	"github.com/dev-abir/tanks/game"
+	"github.com/veandco/go-sdl2/mix"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+type Textures struct {
+	atlas *sdl.Texture // every texture below is a region of the atlas(see atlas.go)
+
+	playerTank     sdl.Rect
+	playerTankHull sdl.Rect // and the turret, instead of playerTank with tank controls
+	turret         sdl.Rect
+	crosshair      sdl.Rect
+	enemyTank      sdl.Rect
+	bullet         sdl.Rect
+	tiles          sdl.Rect
+	tileSize       int32 // of the tiles texture
+
+	explosion   Sprite
+	muzzleFlash Sprite
+	treads      Sprite
+	sparkle     Sprite // the power-ups, and the shield
+}
+
+type SoundEffects struct {
+	shoot     *mix.Chunk
+	explosion *mix.Chunk
+}
+
+type LevelResult int
+
+const (
+	LEVEL_WON LevelResult = iota
+	LEVEL_LOST
+	LEVEL_QUIT // the window was closed, or 'escape' was pressed
+)
+
+//==============PLAYER COLOURS==============
+// the player tanks share one texture, the other players are tinted
+var PLAYER_COLOURS []sdl.Color = []sdl.Color{
+	{R: 255, G: 255, B: 255, A: 255}, // no tint
+	{R: 120, G: 170, B: 255, A: 255},
+}
+
+// the bullet texture is tinted by the kind of the projectile(see game.WEAPONS)
+var PROJECTILE_COLOURS map[game.Projectile]sdl.Color = map[game.Projectile]sdl.Color{
+	game.PROJECTILE_SHELL:    {R: 255, G: 255, B: 255, A: 255}, // no tint
+	game.PROJECTILE_BULLET:   {R: 255, G: 220, B: 90, A: 255},
+	game.PROJECTILE_RICOCHET: {R: 110, G: 230, B: 230, A: 255},
+	game.PROJECTILE_MISSILE:  {R: 255, G: 110, B: 80, A: 255},
+}
+
+//==============POWER-UPS==============
+// every power-up is a sparkle, tinted by it's kind, with a letter over it
+var POWER_UP_COLOURS map[game.PowerUpKind]sdl.Color = map[game.PowerUpKind]sdl.Color{
+	game.POWER_UP_SHIELD:     {R: 120, G: 200, B: 255, A: 255},
+	game.POWER_UP_LIFE:       {R: 255, G: 120, B: 160, A: 255},
+	game.POWER_UP_RAPID_FIRE: {R: 255, G: 220, B: 90, A: 255},
+	game.POWER_UP_FREEZE:     {R: 200, G: 240, B: 255, A: 255},
+	game.POWER_UP_BOMB:       {R: 255, G: 110, B: 80, A: 255},
+	game.POWER_UP_FORTIFY:    {R: 170, G: 170, B: 170, A: 255},
+}
+
+var POWER_UP_LETTERS map[game.PowerUpKind]string = map[game.PowerUpKind]string{
+	game.POWER_UP_SHIELD:     "S",
+	game.POWER_UP_LIFE:       "L",
+	game.POWER_UP_RAPID_FIRE: "R",
+	game.POWER_UP_FREEZE:     "F",
+	game.POWER_UP_BOMB:       "B",
+	game.POWER_UP_FORTIFY:    "W",
+}
+
+var (
+	SHIELD_COLOUR sdl.Color = sdl.Color{R: 120, G: 200, B: 255, A: 160}
+	FROZEN_COLOUR sdl.Color = sdl.Color{R: 150, G: 200, B: 255, A: 255} // of the enemy tanks, while they are frozen
+)
+
+const SHIELD_SIZE float32 = 1.4 // of the width of the tank
+
+// everything that lives longer than one level
+type Session struct {
+	renderer  *sdl.Renderer
+	textures  Textures
+	batch     *Batch // of the sprites, from the atlas of the textures
+	sounds    SoundEffects
+	timestep  float32
+	maxSteps  int // per frame, see Settings.MaxStepsPerFrame
+	players   int
+	bindings  *Bindings
+	gamepads  *Gamepads                // of the players, with the keys
+	mouse     *Mouse                   // of the first player
+	keyboards []*game.InputController  // control the player tanks, if there is no replay
+	replays   []*game.ReplayController // nil, if the keyboard is used
+	recorder  *game.ReplayRecorder
+	paused    bool
+}
+
+func NewSession(renderer *sdl.Renderer, textures Textures, sounds SoundEffects, settings Settings, bindings *Bindings, gamepads *Gamepads, replay *game.Replay, recorder *game.ReplayRecorder) *Session {
+	session := &Session{
+		renderer: renderer,
+		textures: textures,
+		batch:    NewBatch(renderer, textures.atlas),
+		sounds:   sounds,
+		timestep: settings.Timestep,
+		maxSteps: settings.MaxStepsPerFrame,
+		players:  settings.Players,
+		bindings: bindings,
+		gamepads: gamepads,
+		mouse:    NewMouse(settings.Mouse, settings.AutofireInterval),
+		recorder: recorder,
+	}
+	for index := 0; index < settings.Players; index++ {
+		session.keyboards = append(session.keyboards, &game.InputController{})
+		if replay != nil {
+			session.replays = append(session.replays, &game.ReplayController{Replay: replay, Player: index})
+		}
+	}
+	return session
+}
+
+// the controllers of the player tanks
+func (session *Session) PlayerControllers() []game.Controller {
+	var controllers []game.Controller
+	for index := range session.keyboards {
+		if session.replays != nil {
+			controllers = append(controllers, session.replays[index])
+		} else {
+			controllers = append(controllers, session.keyboards[index])
+		}
+	}
+	return controllers
+}
+
+func (session *Session) PlayLevel(world *game.World) LevelResult {
+	timestep := session.timestep
+	replays := session.replays
+	players := session.players
+	session.paused = false
+	session.gamepads.Scan() // gamepads may have been connected or disconnected, before this level
+	session.mouse.Reset()
+	if session.mouse.enabled && (replays == nil) {
+		sdl.ShowCursor(sdl.DISABLE) // the crosshair is drawn instead
+		defer sdl.ShowCursor(sdl.ENABLE)
+	}
+
+	last := time.Now()            // for calculating dt(delta)
+	var accumulator float32 = 0.0 // real time, which has not been simulated yet
+	fpsCounter := 0
+	var fpsTimer float32 = 0.0
+
+	//==============MAIN LOOP==============
+	keyboardState := sdl.GetKeyboardState()           // for handling keyboard events
+	playerShootedInLastFrame := make([]bool, players) // just a flag, to manage player tank shoot events(it ensures that the player tank will not shoot continuously, on pressing down the shoot key)
+	shootPending := make([]bool, players)             // the shoot key was pressed, but the world has not been stepped yet(rendering can be faster than the fixed timestep)
+	for {
+
+		//==============CALCULATING dt(DELTA)==============
+		dt := float32(time.Since(last).Seconds())
+		last = time.Now()
+
+		//==============PRINTING FPS==============
+		fpsTimer += dt
+		if fpsTimer >= 1.0 {
+			fmt.Println("Current FPS: ", fpsCounter)
+			fpsTimer = 0.0
+			fpsCounter = 0
+		}
+
+		//==============EVENT HANDLING==============
+		for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			if event.GetType() == sdl.QUIT {
+				return LEVEL_QUIT
+			}
+			if session.gamepads.HandleEvent(event) || session.mouse.HandleEvent(event) {
+				continue
+			}
+			switch t := event.(type) {
+			case *sdl.ControllerButtonEvent:
+				if (t.Button == sdl.CONTROLLER_BUTTON_START) && (t.State == sdl.PRESSED) {
+					session.paused = !session.paused
+				}
+			case *sdl.KeyboardEvent:
+				if (t.GetType() == sdl.KEYDOWN) && (t.Repeat == 0) {
+					switch t.Keysym.Scancode {
+					case session.bindings.Pause:
+						session.paused = !session.paused
+					case session.bindings.Controls:
+						if !ControlsScreen(session.renderer, session.bindings, players) {
+							return LEVEL_QUIT
+						}
+						last = time.Now() // the time on the controls screen is not played
+						session.mouse.Reset()
+						for index := range playerShootedInLastFrame {
+							playerShootedInLastFrame[index] = false
+						}
+					}
+				}
+				for index, keys := range session.bindings.KeySets(players) {
+					if t.Keysym.Scancode != keys[ACTION_FIRE] {
+						continue
+					}
+					if event.GetType() == sdl.KEYDOWN {
+						if !playerShootedInLastFrame[index] {
+							shootPending[index] = true
+							playerShootedInLastFrame[index] = true
+						}
+					}
+					if event.GetType() == sdl.KEYUP {
+						playerShootedInLastFrame[index] = false
+					}
+				}
+			}
+		}
+
+		// sdl.PumpEvents() // not required
+
+		if keyboardState[session.bindings.Quit] == 1 {
+			return LEVEL_QUIT
+		}
+
+		//==============CALLBACKS==============
+		inputs := make([]game.Input, players)
+		for index, keys := range session.bindings.KeySets(players) {
+			inputs[index] = session.gamepads.Input(index, keys.Input(keyboardState))
+			if (index == MOUSE_PLAYER) && (replays == nil) {
+				inputs[index] = session.mouse.Input(&world.Players[index].Tank, inputs[index], dt)
+			}
+			if inputs[index].Shoot { // pressed on the gamepad
+				shootPending[index] = true
+			}
+			held := (keyboardState[keys[ACTION_FIRE]] == 1) || session.gamepads.Shooting(index) || ((index == MOUSE_PLAYER) && session.mouse.held)
+			if held && world.Players[index].Tank.Weapon.Automatic { // automatic weapons keep shooting, as fast as their cooldown lets them
+				shootPending[index] = true
+			}
+			inputs[index].Shoot = shootPending[index]
+		}
+
+		//==============UPDATING THE WORLD(with a fixed timestep)==============
+		if session.paused {
+			dt = 0.0
+			for index := range shootPending {
+				shootPending[index] = false
+			}
+		}
+		accumulator += dt
+		for steps := 0; (accumulator >= timestep) && (steps < session.maxSteps) && (world.State == game.STATE_RUNNING); steps++ {
+			if replays != nil { // the keyboard is ignored, while playing a replay
+				if replays[0].Finished() {
+					break // the replay has finished, the world stays as it was at the end
+				}
+				for index := range inputs {
+					inputs[index] = replays[index].Next() // only for recording, the replay controls the player tanks itself
+				}
+				if replays[0].Step == len(replays[0].Replay.Inputs)-1 {
+					fmt.Println("==============REPLAY FINISHED==============")
+				}
+			} else {
+				for index := range inputs {
+					session.keyboards[index].Input = inputs[index]
+				}
+			}
+			if session.recorder != nil {
+				if err := session.recorder.Record(inputs); err != nil {
+					HandleError("Failed to write replay: ", err)
+					session.recorder = nil
+				}
+			}
+			world.Step(timestep)
+			accumulator -= timestep
+			for index := range inputs {
+				inputs[index].Shoot = false // shooting only on one step
+				shootPending[index] = false
+			}
+			for _, event := range world.Events {
+				switch event {
+				case game.EVENT_SHOOT:
+					PlaySoundEffect(session.sounds.shoot)
+				case game.EVENT_EXPLOSION, game.EVENT_WALL_DESTROYED:
+					PlaySoundEffect(session.sounds.explosion)
+				}
+			}
+		}
+		if accumulator > timestep {
+			accumulator = 0.0 // dropping the time, which could not be simulated
+		}
+
+		//==============CHECKING WHETHER PLAYER HAS WON OR LOST==============
+		switch world.State {
+		case game.STATE_PLAYER_WON:
+			return LEVEL_WON
+		case game.STATE_PLAYER_LOST:
+			return LEVEL_LOST
+		}
+
+		session.Draw(world)
+
+		//==============UPDATING FPS COUNTER==============
+		fpsCounter += 1
+	}
+}
+
+func (session *Session) Draw(world *game.World) {
+	renderer := session.renderer
+	textures := session.textures
+
+	//==============CLEARING THE SCREEN==============
+	renderer.SetDrawColor(colornames.Bisque.R, colornames.Bisque.G, colornames.Bisque.B, colornames.Bisque.A)
+	renderer.Clear()
+	renderer.SetDrawColor(colornames.Red.R, colornames.Red.G, colornames.Red.B, colornames.Red.A)
+
+	//==============DRAWING==============
+	batch := session.batch
+	tankControls := world.Config().Rules.TankControls
+	DrawTiles(batch, textures, world.Level, false)
+	for index := range world.Explosions {
+		DrawExplosion(batch, textures, world.Explosions[index])
+	}
+	for index := range world.PowerUps {
+		DrawPowerUp(batch, textures, world.PowerUps[index])
+	}
+	for index := range world.Players {
+		player := &world.Players[index]
+		colour := PLAYER_COLOURS[index%len(PLAYER_COLOURS)]
+		if !player.Lost && player.Tank.Visible() {
+			if tankControls {
+				DrawTank(batch, textures, textures.playerTankHull, &textures.turret, &player.Tank, colour)
+			} else {
+				DrawTank(batch, textures, textures.playerTank, nil, &player.Tank, colour)
+			}
+			if player.Tank.Shield > 0.0 {
+				textures.sparkle.DrawTinted(batch, ANIMATION_SPARKLE, player.Tank.Shield, player.Tank.BoundingBox.Centre(), player.Tank.BoundingBox.W*SHIELD_SIZE, 0.0, SHIELD_COLOUR)
+			}
+		}
+		for i := range player.Bullets {
+			DrawBullet(batch, textures, &player.Bullets[i])
+		}
+	}
+	for index := range world.EnemyTanks {
+		tank := &world.EnemyTanks[index]
+		colour := NO_TINT
+		if world.Frozen > 0.0 {
+			colour = FROZEN_COLOUR
+		} else if (tank.Class != nil) && (tank.Class.Tint != [3]uint8{}) {
+			colour = sdl.Color{R: tank.Class.Tint[0], G: tank.Class.Tint[1], B: tank.Class.Tint[2], A: 255}
+		}
+		DrawTank(batch, textures, textures.enemyTank, nil, tank, colour)
+	}
+	for index := range world.EnemyTankBullets {
+		DrawBullet(batch, textures, &world.EnemyTankBullets[index])
+	}
+	DrawTiles(batch, textures, world.Level, true) // trees hide the tanks
+	if session.replays == nil {
+		session.mouse.DrawCrosshair(batch, textures)
+	}
+	batch.Flush()
+	session.DrawHUD(world)
+	renderer.Present()
+}
+
+const (
+	HUD_TEXT_SCALE int32 = 2
+	HUD_MARGIN     int32 = 4 // pixels
+)
+
+/*The letters of the power-ups, lives and score of every player, in the top corners
+(and under them the ammo, for a weapon with a magazine, the seconds left of the power-ups and the kill streak multiplier).*/
+func (session *Session) DrawHUD(world *game.World) {
+	renderer := session.renderer
+	renderer.SetDrawColor(colornames.Black.R, colornames.Black.G, colornames.Black.B, colornames.Black.A)
+	for _, powerUp := range world.PowerUps {
+		if powerUp.Visible() {
+			centre := powerUp.BoundingBox.Centre()
+			DrawText(renderer, POWER_UP_LETTERS[powerUp.Kind], int32(centre.X)-(TextWidth("W", HUD_TEXT_SCALE)/2), int32(centre.Y)-(TextHeight(HUD_TEXT_SCALE)/2), HUD_TEXT_SCALE)
+		}
+	}
+	for index := range world.Players {
+		tank := &world.Players[index].Tank
+		lines := []string{fmt.Sprintf("P%d LIVES:%d SCORE:%d", index+1, tank.Lives, world.Players[index].Score)}
+		if tank.Reloading > 0.0 {
+			lines = append(lines, "RELOADING")
+		} else if tank.Weapon.Magazine > 0 {
+			lines = append(lines, fmt.Sprintf("AMMO:%d/%d", tank.Ammo, tank.Weapon.Magazine))
+		}
+		effects := ""
+		if tank.Shield > 0.0 {
+			effects += fmt.Sprintf(" SHIELD:%.0f", math.Ceil(float64(tank.Shield)))
+		}
+		if tank.RapidFire > 0.0 {
+			effects += fmt.Sprintf(" RAPID:%.0f", math.Ceil(float64(tank.RapidFire)))
+		}
+		if multiplier := world.Multiplier(index); multiplier > 1 {
+			effects += fmt.Sprintf(" COMBO:x%d", multiplier)
+		}
+		if effects != "" {
+			lines = append(lines, effects[1:])
+		}
+		y := HUD_MARGIN + int32(index/2)*(TextHeight(HUD_TEXT_SCALE)+HUD_MARGIN)*2
+		for _, text := range lines {
+			x := HUD_MARGIN
+			if index%2 == 1 {
+				x = SCREEN_WIDTH - TextWidth(text, HUD_TEXT_SCALE) - HUD_MARGIN
+			}
+			DrawText(renderer, text, x, y, HUD_TEXT_SCALE)
+			y += TextHeight(HUD_TEXT_SCALE) + HUD_MARGIN
+		}
+	}
+	if session.paused {
+		DrawTextCentred(renderer, "PAUSED", (SCREEN_HEIGHT-TextHeight(SCREEN_TEXT_SCALE))/2, SCREEN_TEXT_SCALE)
+		help := fmt.Sprintf("%s: RESUME  %s: CONTROLS", sdl.GetScancodeName(session.bindings.Pause), sdl.GetScancodeName(session.bindings.Controls))
+		DrawTextCentred(renderer, help, (SCREEN_HEIGHT+TextHeight(SCREEN_TEXT_SCALE))/2+HUD_MARGIN*2, HUD_TEXT_SCALE)
+	}
+}
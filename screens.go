@@ -0,0 +1,333 @@
+// screens.go
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/image/colornames"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+const (
+	SCREEN_TEXT_SCALE  int32 = 4  // size of one pixel of the font
+	SCREEN_LINE_HEIGHT int32 = 44 // pixels
+)
+
+/*Shows some lines of text for a while(seconds), in the middle of the screen.
+If skippable, 'space' skips the screen.
+Returns false, if the window was closed or 'escape' was pressed.*/
+func ShowScreen(renderer *sdl.Renderer, seconds float32, skippable bool, lines ...string) bool {
+	start := time.Now()
+	for time.Since(start).Seconds() < float64(seconds) {
+		for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				return false
+			case *sdl.KeyboardEvent:
+				if t.GetType() != sdl.KEYDOWN {
+					continue
+				}
+				if t.Keysym.Sym == sdl.K_ESCAPE {
+					return false
+				}
+				if skippable && (t.Keysym.Sym == sdl.K_SPACE) {
+					return true
+				}
+			}
+		}
+
+		renderer.SetDrawColor(colornames.Black.R, colornames.Black.G, colornames.Black.B, colornames.Black.A)
+		renderer.Clear()
+		renderer.SetDrawColor(colornames.Bisque.R, colornames.Bisque.G, colornames.Bisque.B, colornames.Bisque.A)
+		y := (SCREEN_HEIGHT - int32(len(lines))*SCREEN_LINE_HEIGHT) / 2
+		for _, line := range lines {
+			scale := SCREEN_TEXT_SCALE
+			for (scale > 1) && (TextWidth(line, scale) > SCREEN_WIDTH) { // long lines are written smaller
+				scale -= 1
+			}
+			DrawTextCentred(renderer, line, y, scale)
+			y += SCREEN_LINE_HEIGHT
+		}
+		renderer.Present()
+		sdl.Delay(10) // there is no need of hundreds of frames per second, for a still screen
+	}
+	return true
+}
+
+const (
+	CONTROLS_TEXT_SCALE  int32 = 2
+	CONTROLS_LINE_HEIGHT int32 = 20 // pixels
+	CONTROLS_MARGIN      int32 = 20 // pixels, on the left and the right
+)
+
+/*Shows the keys(of this many players), and lets the player change them.
+'up' and 'down' select an action, 'return' waits for the new key of that action('escape' cancels),
+'backspace' restores the default keys, and 'escape' goes back.
+A key bound to another action is not accepted. The bindings are saved on every change.
+Returns false, if the window was closed.*/
+func ControlsScreen(renderer *sdl.Renderer, bindings *Bindings, players int) bool {
+	selected := 0
+	waiting := false // for the new key of the selected action
+	message := ""    // the last error
+	for {
+		list := bindings.List(players)
+		for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				return false
+			case *sdl.KeyboardEvent:
+				if (t.GetType() != sdl.KEYDOWN) || (t.Repeat != 0) {
+					continue
+				}
+				key := t.Keysym.Scancode
+				if waiting {
+					waiting = false
+					if key == sdl.SCANCODE_ESCAPE {
+						continue
+					}
+					old := *list[selected].Key
+					*list[selected].Key = key
+					if err := bindings.Validate(); err != nil {
+						*list[selected].Key = old
+						message = err.Error()
+						continue
+					}
+					message = ""
+					if err := bindings.Save(); err != nil {
+						message = "FAILED TO SAVE: " + err.Error()
+					}
+					continue
+				}
+				switch key {
+				case sdl.SCANCODE_ESCAPE:
+					return true
+				case sdl.SCANCODE_UP:
+					selected = (selected + len(list) - 1) % len(list)
+				case sdl.SCANCODE_DOWN:
+					selected = (selected + 1) % len(list)
+				case sdl.SCANCODE_RETURN:
+					waiting = true
+					message = ""
+				case sdl.SCANCODE_BACKSPACE:
+					path := bindings.path
+					*bindings = DEFAULT_BINDINGS
+					bindings.path = path
+					message = ""
+					if err := bindings.Save(); err != nil {
+						message = "FAILED TO SAVE: " + err.Error()
+					}
+				}
+			}
+		}
+
+		renderer.SetDrawColor(colornames.Black.R, colornames.Black.G, colornames.Black.B, colornames.Black.A)
+		renderer.Clear()
+		renderer.SetDrawColor(colornames.Bisque.R, colornames.Bisque.G, colornames.Bisque.B, colornames.Bisque.A)
+		y := CONTROLS_MARGIN
+		DrawTextCentred(renderer, "CONTROLS", y, SCREEN_TEXT_SCALE)
+		y += SCREEN_LINE_HEIGHT
+		for index, binding := range list {
+			label := "  " + binding.Label
+			key := sdl.GetScancodeName(*binding.Key)
+			if index == selected {
+				label = "> " + binding.Label
+				if waiting {
+					key = "PRESS A KEY"
+				}
+			}
+			DrawText(renderer, label, CONTROLS_MARGIN, y, CONTROLS_TEXT_SCALE)
+			DrawText(renderer, key, SCREEN_WIDTH-CONTROLS_MARGIN-TextWidth(key, CONTROLS_TEXT_SCALE), y, CONTROLS_TEXT_SCALE)
+			y += CONTROLS_LINE_HEIGHT
+		}
+		y += CONTROLS_LINE_HEIGHT / 2
+		for _, line := range []string{"UP/DOWN: SELECT  RETURN: CHANGE", "BACKSPACE: DEFAULTS  ESCAPE: BACK"} {
+			DrawTextCentred(renderer, line, y, CONTROLS_TEXT_SCALE)
+			y += CONTROLS_LINE_HEIGHT
+		}
+		if message != "" {
+			renderer.SetDrawColor(colornames.Red.R, colornames.Red.G, colornames.Red.B, colornames.Red.A)
+			scale := CONTROLS_TEXT_SCALE
+			for (scale > 1) && (TextWidth(message, scale) > SCREEN_WIDTH) {
+				scale -= 1
+			}
+			DrawTextCentred(renderer, message, y, scale)
+		}
+		renderer.Present()
+		sdl.Delay(10)
+	}
+}
+
+/*Lists the asset packs, for choosing one.
+Returns the index of the chosen pack, and false if the window was closed or 'escape' was pressed.*/
+func PackScreen(renderer *sdl.Renderer, packs []AssetPack) (int, bool) {
+	selected := 0
+	for {
+		for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				return 0, false
+			case *sdl.KeyboardEvent:
+				if t.GetType() != sdl.KEYDOWN {
+					continue
+				}
+				switch t.Keysym.Scancode {
+				case sdl.SCANCODE_ESCAPE:
+					return 0, false
+				case sdl.SCANCODE_UP:
+					selected = (selected + len(packs) - 1) % len(packs)
+				case sdl.SCANCODE_DOWN:
+					selected = (selected + 1) % len(packs)
+				case sdl.SCANCODE_RETURN, sdl.SCANCODE_SPACE:
+					return selected, true
+				}
+			}
+		}
+
+		renderer.SetDrawColor(colornames.Black.R, colornames.Black.G, colornames.Black.B, colornames.Black.A)
+		renderer.Clear()
+		renderer.SetDrawColor(colornames.Bisque.R, colornames.Bisque.G, colornames.Bisque.B, colornames.Bisque.A)
+		y := CONTROLS_MARGIN
+		DrawTextCentred(renderer, "ASSET PACK", y, SCREEN_TEXT_SCALE)
+		y += SCREEN_LINE_HEIGHT
+		for index, pack := range packs {
+			label := "  " + strings.ToUpper(pack.Name)
+			if index == selected {
+				label = "> " + strings.ToUpper(pack.Name)
+			}
+			DrawText(renderer, label, CONTROLS_MARGIN, y, CONTROLS_TEXT_SCALE)
+			y += CONTROLS_LINE_HEIGHT
+		}
+		y += CONTROLS_LINE_HEIGHT / 2
+		DrawTextCentred(renderer, "UP/DOWN: SELECT  RETURN: PLAY", y, CONTROLS_TEXT_SCALE)
+		renderer.Present()
+		sdl.Delay(10)
+	}
+}
+
+/*Lets the player write their initials, for the high scores.
+'up' and 'down' change the selected letter(or the letter is typed), 'left', 'right' and 'backspace' move,
+and 'return' is done.
+Returns the initials, and false if the window was closed or 'escape' was pressed.*/
+func InitialsScreen(renderer *sdl.Renderer, title string, score int) (string, bool) {
+	initials := []byte(strings.Repeat(INITIALS_CHARACTERS[:1], INITIALS_LENGTH))
+	selected := 0
+	for {
+		for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				return "", false
+			case *sdl.KeyboardEvent:
+				if t.GetType() != sdl.KEYDOWN {
+					continue
+				}
+				character := strings.ToUpper(sdl.GetKeyName(t.Keysym.Sym))
+				letter := strings.Index(INITIALS_CHARACTERS, character)
+				if (len(character) == 1) && (letter >= 0) {
+					initials[selected] = INITIALS_CHARACTERS[letter]
+					if selected < INITIALS_LENGTH-1 {
+						selected += 1
+					}
+					continue
+				}
+				letter = strings.IndexByte(INITIALS_CHARACTERS, initials[selected])
+				switch t.Keysym.Scancode {
+				case sdl.SCANCODE_ESCAPE:
+					return "", false
+				case sdl.SCANCODE_UP:
+					initials[selected] = INITIALS_CHARACTERS[(letter+1)%len(INITIALS_CHARACTERS)]
+				case sdl.SCANCODE_DOWN:
+					initials[selected] = INITIALS_CHARACTERS[(letter+len(INITIALS_CHARACTERS)-1)%len(INITIALS_CHARACTERS)]
+				case sdl.SCANCODE_LEFT, sdl.SCANCODE_BACKSPACE:
+					if selected > 0 {
+						selected -= 1
+					}
+				case sdl.SCANCODE_RIGHT:
+					if selected < INITIALS_LENGTH-1 {
+						selected += 1
+					}
+				case sdl.SCANCODE_RETURN:
+					return string(initials), true
+				}
+			}
+		}
+
+		renderer.SetDrawColor(colornames.Black.R, colornames.Black.G, colornames.Black.B, colornames.Black.A)
+		renderer.Clear()
+		renderer.SetDrawColor(colornames.Bisque.R, colornames.Bisque.G, colornames.Bisque.B, colornames.Bisque.A)
+		y := (SCREEN_HEIGHT - 5*SCREEN_LINE_HEIGHT) / 2
+		DrawTextCentred(renderer, title, y, SCREEN_TEXT_SCALE)
+		y += SCREEN_LINE_HEIGHT
+		DrawTextCentred(renderer, fmt.Sprintf("SCORE: %d", score), y, SCREEN_TEXT_SCALE)
+		y += SCREEN_LINE_HEIGHT * 2
+		// the letters are spaced out, the selected one is underlined
+		letterWidth := TextWidth("W ", SCREEN_TEXT_SCALE)
+		x := (SCREEN_WIDTH - letterWidth*int32(INITIALS_LENGTH)) / 2
+		for index := range initials {
+			text := string(initials[index])
+			DrawText(renderer, text, x, y, SCREEN_TEXT_SCALE)
+			if index == selected {
+				DrawText(renderer, "_", x, y+SCREEN_TEXT_SCALE*2, SCREEN_TEXT_SCALE)
+			}
+			x += letterWidth
+		}
+		y += SCREEN_LINE_HEIGHT
+		DrawTextCentred(renderer, "UP/DOWN OR TYPE: LETTER  RETURN: DONE", y, CONTROLS_TEXT_SCALE)
+		renderer.Present()
+		sdl.Delay(10)
+	}
+}
+
+/*Shows the high scores for a while(seconds), the entries at the highlighted indices in another colour.
+'space' or 'return' skips the screen.
+Returns false, if the window was closed or 'escape' was pressed.*/
+func HighScoresScreen(renderer *sdl.Renderer, scores *HighScores, seconds float32, highlighted ...int) bool {
+	start := time.Now()
+	for time.Since(start).Seconds() < float64(seconds) {
+		for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				return false
+			case *sdl.KeyboardEvent:
+				if t.GetType() != sdl.KEYDOWN {
+					continue
+				}
+				switch t.Keysym.Scancode {
+				case sdl.SCANCODE_ESCAPE:
+					return false
+				case sdl.SCANCODE_SPACE, sdl.SCANCODE_RETURN:
+					return true
+				}
+			}
+		}
+
+		renderer.SetDrawColor(colornames.Black.R, colornames.Black.G, colornames.Black.B, colornames.Black.A)
+		renderer.Clear()
+		renderer.SetDrawColor(colornames.Bisque.R, colornames.Bisque.G, colornames.Bisque.B, colornames.Bisque.A)
+		y := CONTROLS_MARGIN
+		DrawTextCentred(renderer, "HIGH SCORES", y, SCREEN_TEXT_SCALE)
+		y += SCREEN_LINE_HEIGHT
+		if len(scores.Entries) == 0 {
+			DrawTextCentred(renderer, "NO SCORES YET", y, CONTROLS_TEXT_SCALE)
+		}
+		for index, entry := range scores.Entries {
+			renderer.SetDrawColor(colornames.Bisque.R, colornames.Bisque.G, colornames.Bisque.B, colornames.Bisque.A)
+			for _, other := range highlighted {
+				if index == other {
+					renderer.SetDrawColor(colornames.Gold.R, colornames.Gold.G, colornames.Gold.B, colornames.Gold.A)
+				}
+			}
+			// rank, initials and score on the left, level and date on the right
+			left := fmt.Sprintf("%2d. %-*s %7d", index+1, INITIALS_LENGTH, entry.Initials, entry.Score)
+			right := fmt.Sprintf("L%d %s", entry.Level, entry.Date)
+			DrawText(renderer, left, CONTROLS_MARGIN, y, CONTROLS_TEXT_SCALE)
+			DrawText(renderer, right, SCREEN_WIDTH-CONTROLS_MARGIN-TextWidth(right, CONTROLS_TEXT_SCALE), y, CONTROLS_TEXT_SCALE)
+			y += CONTROLS_LINE_HEIGHT * 3 / 2
+		}
+		renderer.Present()
+		sdl.Delay(10)
+	}
+	return true
+}
@@ -0,0 +1,224 @@
+// sprites.go
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/dev-abir/tanks/game"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+/*
+
+A sprite sheet is a texture of frames of the same size(row by row), with a sidecar file describing the animations in it.
+The sidecar file has the name of the texture, with .json(resources/treads.png -> resources/treads.json):
+
+	{
+		"frameWidth": 16,
+		"frameHeight": 16,
+		"animations": {
+			"muzzleFlash": {"frames": [0, 1, 2, 3], "durations": [0.02, 0.02, 0.03, 0.03], "loop": "once", "origin": [0, 8]}
+		}
+	}
+
+	frames: the frames of the animation, by their index in the sheet(every frame of the sheet, in order, if missing)
+	durations: seconds of every frame, or one duration for all of them
+	loop: "once"(stops at the last frame), "loop" or "pingpong"(forwards, then backwards), "once" if missing
+	origin: the pixel of the frame, which is drawn at the position of the object(and rotated around), the centre if missing
+
+So a new texture needs only a new sidecar file, not new code.
+
+*/
+
+const (
+	LOOP_ONCE      string = "once"
+	LOOP_REPEAT    string = "loop"
+	LOOP_PING_PONG string = "pingpong"
+
+	SIDECAR_EXTENSION string = ".json"
+)
+
+type Animation struct {
+	Frames    []int     `json:"frames"`
+	Durations []float32 `json:"durations"`
+	Loop      string    `json:"loop"`
+	Origin    []float32 `json:"origin"`
+
+	duration float32 // of all the frames
+}
+
+type SpriteSheet struct {
+	FrameWidth  int32                 `json:"frameWidth"`
+	FrameHeight int32                 `json:"frameHeight"`
+	Animations  map[string]*Animation `json:"animations"`
+
+	columns int32 // of frames in the texture
+}
+
+// the sidecar file of a texture
+func SidecarPath(texturePath string) string {
+	return strings.TrimSuffix(texturePath, filepath.Ext(texturePath)) + SIDECAR_EXTENSION
+}
+
+/*Loads the sidecar file of the texture, and checks it against the image of the texture.
+names are the animations needed by the game.*/
+func LoadSpriteSheet(assets *Assets, texturePath string, image *sdl.Surface, names ...string) (*SpriteSheet, error) {
+	data, location, err := assets.ReadFile(SidecarPath(texturePath))
+	if err != nil {
+		return nil, err
+	}
+	var sheet SpriteSheet
+	if err := json.Unmarshal(data, &sheet); err != nil {
+		return nil, fmt.Errorf("%s: %v", location, err)
+	}
+	if err := sheet.validate(image, names); err != nil {
+		return nil, fmt.Errorf("%s: %v", location, err)
+	}
+	return &sheet, nil
+}
+
+func (sheet *SpriteSheet) validate(image *sdl.Surface, names []string) error {
+	if (sheet.FrameWidth <= 0) || (sheet.FrameHeight <= 0) {
+		return errors.New("frameWidth and frameHeight must be more than 0")
+	}
+	sheet.columns = image.W / sheet.FrameWidth
+	rows := image.H / sheet.FrameHeight
+	if (sheet.columns == 0) || (rows == 0) {
+		return fmt.Errorf("the image is %dx%d, smaller than one frame(%dx%d)", image.W, image.H, sheet.FrameWidth, sheet.FrameHeight)
+	}
+	for _, name := range names {
+		if sheet.Animations[name] == nil {
+			return fmt.Errorf("there is no animation %q", name)
+		}
+	}
+	for name, animation := range sheet.Animations {
+		if err := animation.validate(sheet, int(sheet.columns*rows)); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+func (animation *Animation) validate(sheet *SpriteSheet, frames int) error {
+	if len(animation.Frames) == 0 {
+		for frame := 0; frame < frames; frame++ {
+			animation.Frames = append(animation.Frames, frame)
+		}
+	}
+	for _, frame := range animation.Frames {
+		if (frame < 0) || (frame >= frames) {
+			return fmt.Errorf("there is no frame %d, the sheet has %d frames", frame, frames)
+		}
+	}
+	if (len(animation.Durations) != 1) && (len(animation.Durations) != len(animation.Frames)) {
+		return fmt.Errorf("%d durations for %d frames, give one duration, or one for every frame", len(animation.Durations), len(animation.Frames))
+	}
+	animation.duration = 0.0
+	for index := range animation.Frames {
+		duration := animation.frameDuration(index)
+		if duration <= 0.0 {
+			return errors.New("durations must be more than 0")
+		}
+		animation.duration += duration
+	}
+	switch animation.Loop {
+	case "":
+		animation.Loop = LOOP_ONCE
+	case LOOP_ONCE, LOOP_REPEAT, LOOP_PING_PONG:
+	default:
+		return fmt.Errorf("unknown loop %q(once, loop or pingpong)", animation.Loop)
+	}
+	switch len(animation.Origin) {
+	case 0:
+		animation.Origin = []float32{float32(sheet.FrameWidth) / 2.0, float32(sheet.FrameHeight) / 2.0}
+	case 2:
+	default:
+		return errors.New("origin must be [x, y]")
+	}
+	return nil
+}
+
+func (animation *Animation) frameDuration(index int) float32 {
+	if len(animation.Durations) == 1 {
+		return animation.Durations[0]
+	}
+	return animation.Durations[index]
+}
+
+// seconds, of one run of the animation
+func (animation *Animation) Duration() float32 {
+	return animation.duration
+}
+
+// a "once" animation has finished at this time
+func (animation *Animation) Finished(time float32) bool {
+	return (animation.Loop == LOOP_ONCE) && (time >= animation.duration)
+}
+
+// the frame of the sheet, shown at this time(seconds from the start of the animation)
+func (animation *Animation) Frame(time float32) int {
+	switch animation.Loop {
+	case LOOP_REPEAT:
+		time = float32(math.Mod(float64(time), float64(animation.duration)))
+	case LOOP_PING_PONG:
+		time = float32(math.Mod(float64(time), 2.0*float64(animation.duration)))
+		if time >= animation.duration {
+			time = (2.0 * animation.duration) - time
+		}
+	}
+	for index, frame := range animation.Frames {
+		time -= animation.frameDuration(index)
+		if time < 0.0 {
+			return frame
+		}
+	}
+	return animation.Frames[len(animation.Frames)-1] // a "once" animation stays at it's last frame
+}
+
+// the rectangle of the frame in the texture
+func (sheet *SpriteSheet) Cell(frame int) sdl.Rect {
+	return sdl.Rect{
+		X: (int32(frame) % sheet.columns) * sheet.FrameWidth,
+		Y: (int32(frame) / sheet.columns) * sheet.FrameHeight,
+		W: sheet.FrameWidth,
+		H: sheet.FrameHeight,
+	}
+}
+
+//==============DRAWING==============
+
+// a sprite sheet, with it's texture(a region of the atlas)
+type Sprite struct {
+	region sdl.Rect
+	sheet  *SpriteSheet
+}
+
+func (sprite Sprite) Animation(name string) *Animation {
+	return sprite.sheet.Animations[name]
+}
+
+/*Draws the frame of the animation at this time, with the origin of the frame at position.
+The frame is scaled to width(keeping the shape of the frame), and rotated by angle(degrees) around the origin.*/
+func (sprite Sprite) Draw(batch *Batch, name string, time float32, position game.Point, width float32, angle float32) {
+	sprite.DrawTinted(batch, name, time, position, width, angle, NO_TINT)
+}
+
+// like Draw, with the colour of the frame multiplied by colour
+func (sprite Sprite) DrawTinted(batch *Batch, name string, time float32, position game.Point, width float32, angle float32, colour sdl.Color) {
+	animation := sprite.Animation(name)
+	cell := sprite.sheet.Cell(animation.Frame(time))
+	cell.X += sprite.region.X
+	cell.Y += sprite.region.Y
+	scale := width / float32(sprite.sheet.FrameWidth)
+	origin := game.Point{X: animation.Origin[0] * scale, Y: animation.Origin[1] * scale}
+	batch.AddRotated(cell, game.Rect{
+		X: position.X - origin.X,
+		Y: position.Y - origin.Y,
+		W: float32(sprite.sheet.FrameWidth) * scale,
+		H: float32(sprite.sheet.FrameHeight) * scale}, angle, origin, colour)
+}
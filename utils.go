@@ -2,120 +2,127 @@
 package main
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"math"
-	"math/rand"
+	"os"
 
-	"github.com/veandco/go-sdl2/img"
+	"github.com/dev-abir/tanks/game"
 	"github.com/veandco/go-sdl2/mix"
 	"github.com/veandco/go-sdl2/sdl"
 )
 
-func RemoveElementFromBulletSlice(slice []Bullet, index int) []Bullet {
-	// source : https://stackoverflow.com/a/37335777
-	// TODO : How to make it generic, i.e., it can remove an element from a slice of any kind
-	slice[index] = slice[len(slice)-1] // No bounds check = panic(on index out of bounds)
-	return slice[:len(slice)-1]
+func PlaySoundEffect(soundEffect *mix.Chunk) {
+	if soundEffect == nil {
+		return // it could not be loaded
+	}
+	if _, err := soundEffect.Play(-1, 0); err != nil {
+		HandleError("Error on playing sound effect: ", err)
+	}
 }
 
-func RemoveElementFromEnemyTankSlice(slice []EnemyTank, index int) []EnemyTank {
-	// source : https://stackoverflow.com/a/37335777
-	// TODO : How to make it generic, i.e., it can remove an element from a slice of any kind
-	slice[index] = slice[len(slice)-1] // No bounds check = panic(on index out of bounds)
-	return slice[:len(slice)-1]
+func DrawTexture(batch *Batch, texture sdl.Rect, boundingBox *game.Rect, rotationAngle float32, colour sdl.Color) {
+	batch.Add(texture, *boundingBox, rotationAngle, colour)
 }
 
-func RemoveElementFromExplosionSlice(slice []Explosion, index int) []Explosion {
-	// source : https://stackoverflow.com/a/37335777
-	// TODO : How to make it generic, i.e., it can remove an element from a slice of any kind
-	slice[index] = slice[len(slice)-1] // No bounds check = panic(on index out of bounds)
-	return slice[:len(slice)-1]
+func DrawBullet(batch *Batch, textures Textures, bullet *game.Bullet) {
+	colour, found := PROJECTILE_COLOURS[bullet.Projectile]
+	if !found {
+		colour = NO_TINT
+	}
+	DrawTexture(batch, textures.bullet, &bullet.BoundingBox, bullet.RotationAngle, colour)
 }
 
-func DegreeToRadian(angleInDegree float64) float64 {
-	return angleInDegree * (math.Pi / 180.0)
+// a sparkle tinted by the kind of the power-up(it's letter is drawn over it, see DrawHUD), blinking before it is gone
+func DrawPowerUp(batch *Batch, textures Textures, powerUp game.PowerUp) {
+	if !powerUp.Visible() {
+		return
+	}
+	textures.sparkle.DrawTinted(batch, ANIMATION_SPARKLE, powerUp.Age, powerUp.BoundingBox.Centre(), powerUp.BoundingBox.W, 0.0, POWER_UP_COLOURS[powerUp.Kind])
 }
 
-func GetTexture(texturePath string, renderer *sdl.Renderer) (*sdl.Surface, *sdl.Texture, int) {
-	image, err := img.Load(texturePath)
-	if err != nil {
-		HandleError("Failed to load image "+texturePath, err)
-		return nil, nil, ERROR_FAILED_TO_LOAD_IMAGE
+func DrawExplosion(batch *Batch, textures Textures, explosion game.Explosion) {
+	time := explosion.Progress() * textures.explosion.Animation(ANIMATION_EXPLOSION).Duration()
+	textures.explosion.Draw(batch, ANIMATION_EXPLOSION, time, explosion.Position, EXPLOSION_SIZE*explosion.Scale, 0.0)
+}
+
+/*The tank(turned by the heading of it's hull), the treads over it, the turret(nil, if it is a part of the texture of the tank)
+turned by the aim of the tank, and the muzzle flash at the nose of the turret, after a shot.*/
+func DrawTank(batch *Batch, textures Textures, texture sdl.Rect, turret *sdl.Rect, tank *game.Tank, colour sdl.Color) {
+	DrawTexture(batch, texture, &tank.BoundingBox, tank.RotationAngle, colour)
+	centre := tank.BoundingBox.Centre()
+	textures.treads.Draw(batch, ANIMATION_TREADS, tank.Travelled/TREADS_PIXELS_PER_SECOND, centre, tank.BoundingBox.W, tank.RotationAngle)
+	if turret != nil {
+		DrawTexture(batch, *turret, &tank.BoundingBox, tank.AimAngle(), colour)
 	}
 
-	texture, err := renderer.CreateTextureFromSurface(image)
-	if err != nil {
-		HandleError("Failed to create texture: ", err)
-		return image, nil, ERROR_FAILED_TO_CREATE_TEXTURE_FROM_IMAGE
+	if (tank.SinceShot < 0.0) || textures.muzzleFlash.Animation(ANIMATION_MUZZLE_FLASH).Finished(tank.SinceShot) {
+		return
+	}
+	angle := game.DegreeToRadian(float64(tank.AimAngle()))
+	nose := game.Point{
+		X: centre.X + (tank.BoundingBox.W / 2.0 * float32(math.Cos(angle))),
+		Y: centre.Y + (tank.BoundingBox.W / 2.0 * float32(math.Sin(angle))),
 	}
-	return image, texture, 0
+	textures.muzzleFlash.Draw(batch, ANIMATION_MUZZLE_FLASH, tank.SinceShot, nose, MUZZLE_FLASH_SIZE, tank.AimAngle())
 }
 
-func GetSoundEffect(path string) *mix.Chunk {
-	result, err := mix.LoadWAV(path)
+func ReadReplay(path string) (*game.Replay, error) {
+	file, err := os.Open(path)
 	if err != nil {
-		HandleError("Cannot load "+EXPLOSION_SOUND_PATH+", you may play without it: ", err)
+		return nil, err
 	}
-	return result
+	defer file.Close()
+	return game.ReadReplay(file)
 }
 
-func PlaySoundEffect(soundEffect *mix.Chunk) {
-	if _, err := soundEffect.Play(-1, 0); err != nil {
-		HandleError("Error on playing sound effect: ", err)
+func LoadLevel(assets *Assets, path string) (*game.Level, error) {
+	data, err := assets.ReadUserFile(path)
+	if err != nil {
+		return nil, err
 	}
+	return game.ParseLevel(bytes.NewReader(data))
 }
 
-func GetRandomFloat32(min float32, max float32, r *rand.Rand) float32 {
-	return min + (rand.Float32() * (max - min))
-}
-
-func DrawTexture(renderer *sdl.Renderer, texture *sdl.Texture, boundingBox *sdl.FRect, rotationAngle float32) {
-	/*
-
-		TODO : For some reason CopyExF is not working.......
-
-	*/
-
-	renderer.CopyEx(texture, nil, &sdl.Rect{
-		int32(boundingBox.X),
-		int32(boundingBox.Y),
-		int32(boundingBox.W),
-		int32(boundingBox.H)}, float64(rotationAngle), nil, sdl.FLIP_NONE)
-}
-
-func SetPositionOfEnemyTanks(enemyTanks []EnemyTank, playerTankBoundingBox sdl.FRect, r *rand.Rand) {
-	for index := range enemyTanks {
-		enemyTanks[index].boundingBox = GetPositionOfOneEnemyTank(enemyTanks[index].boundingBox, enemyTanks[:index], playerTankBoundingBox, r)
+// draws the trees(they are drawn over the tanks) or all the other tiles
+func DrawTiles(batch *Batch, textures Textures, level *game.Level, trees bool) {
+	tileSize := textures.tileSize
+	for row := 0; row < level.Rows; row++ {
+		for column := 0; column < level.Columns; column++ {
+			tile := level.TileAt(column, row)
+			if (tile == game.TILE_EMPTY) || ((tile == game.TILE_TREES) != trees) {
+				continue
+			}
+			var textureRow int32 = 0
+			if level.Damaged(column, row) {
+				textureRow = 1 // the second row of the texture has the damaged tiles
+			}
+			batch.Add(sdl.Rect{
+				X: textures.tiles.X + ((int32(tile) - 1) * tileSize), // there is no texture for the empty tile
+				Y: textures.tiles.Y + (textureRow * tileSize),
+				W: tileSize,
+				H: tileSize}, level.TileRect(column, row), 0.0, NO_TINT)
+		}
 	}
 }
 
-func GetPositionOfOneEnemyTank(enemyTankBoundingBox sdl.FRect, otherEnemyTanks []EnemyTank, playerTankBoundingBox sdl.FRect, r *rand.Rand) sdl.FRect {
-	experimentalTankBoundingBox := sdl.FRect{
-		X: r.Float32() * float32(SCREEN_WIDTH),
-		Y: r.Float32() * float32(SCREEN_HEIGHT),
-		W: enemyTankBoundingBox.W,
-		H: enemyTankBoundingBox.H,
-	}
-	if !ValidPosition(experimentalTankBoundingBox, otherEnemyTanks, playerTankBoundingBox) {
-		return GetPositionOfOneEnemyTank(enemyTankBoundingBox, otherEnemyTanks, playerTankBoundingBox, r)
+func LoadCampaign(assets *Assets, path string) (*game.Campaign, error) {
+	data, err := assets.ReadUserFile(path)
+	if err != nil {
+		return nil, err
 	}
-	return experimentalTankBoundingBox
+	return game.ParseCampaign(bytes.NewReader(data))
 }
 
-func ValidPosition(experimentalTankBoundingBox sdl.FRect, otherEnemyTanks []EnemyTank, playerTankBoundingBox sdl.FRect) bool {
-	for idx := range otherEnemyTanks {
-		if experimentalTankBoundingBox.HasIntersection(&otherEnemyTanks[idx].boundingBox) {
-			return false
+// the hash of the campaign, with the maps of it's levels(see game.Campaign.Hash)
+func CampaignHash(assets *Assets, campaign *game.Campaign) ([sha256.Size]byte, error) {
+	maps := make([][]byte, len(campaign.Levels))
+	for index, levelSettings := range campaign.Levels {
+		data, err := assets.ReadUserFile(levelSettings.Map)
+		if err != nil {
+			return [sha256.Size]byte{}, err
 		}
+		maps[index] = data
 	}
-	if experimentalTankBoundingBox.HasIntersection(&playerTankBoundingBox) || !IsInsideWindow(experimentalTankBoundingBox) {
-		return false
-	}
-	return true
-}
-
-func IsInsideWindow(bounds sdl.FRect) bool {
-	return ((bounds.X > 0.0) &&
-		(bounds.Y > 0.0) &&
-		((bounds.X + bounds.W) < float32(SCREEN_WIDTH)) &&
-		((bounds.Y + bounds.H) < float32(SCREEN_HEIGHT)))
+	return campaign.Hash(maps)
 }